@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// batchCancelTimeout bounds each cancellation sent when a batch is interrupted.
+const batchCancelTimeout = 5 * time.Second
+
+// batchResult is the NDJSON line printed for each query of a batch.
+type batchResult struct {
+	Query   string            `json:"query"`
+	SID     string            `json:"sid,omitempty"`
+	Results []json.RawMessage `json:"results"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// batchFlags holds the flags of the batch command.
+type batchFlags struct {
+	file             *string
+	earliest         *string
+	latest           *string
+	last             *string
+	concurrency      *int
+	timeout          *time.Duration
+	silent           *bool
+	allowDestructive *bool
+}
+
+// addBatchFlags defines the flags of the batch command.
+func addBatchFlags(fs *flag.FlagSet) *batchFlags {
+	flags := &batchFlags{
+		file:             fs.String("file", "", "File with one SPL query per line ('-' for stdin); blank lines and lines starting with '#' are skipped"),
+		earliest:         fs.String("earliest", "", "Search earliest time for every query (e.g., -1h, @d)"),
+		latest:           fs.String("latest", "", "Search latest time for every query (e.g., now, @d)"),
+		last:             fs.String("last", "", lastFlagUsage),
+		concurrency:      fs.Int("concurrency", 4, "Maximum number of searches running at once"),
+		timeout:          fs.Duration("timeout", 10*time.Minute, "Timeout for each search job to complete"),
+		silent:           fs.Bool("silent", false, "Suppress progress messages"),
+		allowDestructive: fs.Bool("allow-destructive", false, "Allow searches that modify indexed data ('| delete', '| collect')"),
+	}
+	fs.StringVar(flags.file, "f", "", "Shorthand for --file")
+	return flags
+}
+
+// batchCmd runs several searches concurrently and prints one NDJSON line per
+// search, in input order. On Ctrl-C or SIGTERM every job that is still running
+// is cancelled before the command exits.
+func batchCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("batch", "batch")
+	flags := addBatchFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if *flags.file == "" {
+		return errors.New("--file is required")
+	}
+	if *flags.concurrency < 1 {
+		return errors.New("--concurrency must be >= 1")
+	}
+	queries, err := readBatchQueries(*flags.file)
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries found in '%s'", *flags.file)
+	}
+	for i, query := range queries {
+		if err := checkDestructive(query, *flags.allowDestructive); err != nil {
+			return fmt.Errorf("query %d: %w", i+1, err)
+		}
+	}
+	var opts splunk.SearchOptions
+	if opts.Earliest, opts.Latest, err = resolveTimeRange(fs, *flags.earliest, *flags.latest, *flags.last, &baseCfg); err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	// The per-job progress messages of the client would interleave; batch
+	// reports progress itself.
+	client, err := splunk.NewClient(&baseCfg, true)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+	progress := func(format string, a ...any) {
+		if !*flags.silent {
+			fmt.Fprintf(os.Stderr, format, a...)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	tracker := newJobTracker()
+	results := make([]batchResult, len(queries))
+	sem := make(chan struct{}, *flags.concurrency)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = batchResult{Query: query, Error: "not started: batch was interrupted"}
+				return
+			}
+			results[i] = runBatchQuery(ctx, client, query, opts, baseCfg.Limit, *flags.timeout, tracker)
+			if results[i].Error != "" {
+				progress("[%d/%d] failed: %s\n", i+1, len(queries), results[i].Error)
+			} else {
+				progress("[%d/%d] done: %s (%d results)\n", i+1, len(queries), results[i].SID, len(results[i].Results))
+			}
+		}()
+	}
+	wg.Wait()
+
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		stop()
+		cancelled, failed := tracker.cancelAll(client, batchCancelTimeout)
+		for _, sid := range cancelled {
+			fmt.Fprintf(os.Stderr, "Cancelled job %s\n", sid)
+		}
+		for _, sid := range failed {
+			fmt.Fprintf(os.Stderr, "Warning: could not cancel job %s\n", sid)
+		}
+	}
+
+	failures := 0
+	enc := json.NewEncoder(os.Stdout)
+	for _, r := range results {
+		if r.Error != "" {
+			failures++
+		}
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if interrupted {
+		return errors.New("batch was interrupted")
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d searches failed", failures, len(results))
+	}
+	return nil
+}
+
+// runBatchQuery starts one search, waits for it, and fetches its results.
+func runBatchQuery(ctx context.Context, client *splunk.Client, query string, opts splunk.SearchOptions, limit int, timeout time.Duration, tracker *jobTracker) batchResult {
+	result := batchResult{Query: query}
+	sid, _, err := client.StartSearch(query, opts)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.SID = sid
+	tracker.add(sid)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := client.WaitForJob(waitCtx, sid); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("job did not finish within %v", timeout)
+		} else if errors.Is(err, context.Canceled) {
+			err = errors.New("interrupted")
+		}
+		result.Error = err.Error()
+		return result
+	}
+	tracker.remove(sid)
+
+	rows, err := client.Results(ctx, sid, splunk.ResultsOptions{Limit: limit})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Results = rows
+	return result
+}
+
+// readBatchQueries reads one query per line from path ('-' for stdin).
+func readBatchQueries(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read queries from '%s': %w", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var queries []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for first := true; scanner.Scan(); first = false {
+		text := scanner.Text()
+		if first {
+			text = strings.TrimPrefix(text, utf8BOM)
+		}
+		line := strings.TrimSpace(text)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read queries from '%s': %w", path, err)
+	}
+	return queries, nil
+}
+
+// jobTracker records the SIDs of jobs that are still running, so they can all
+// be cancelled when a batch is interrupted.
+type jobTracker struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+func newJobTracker() *jobTracker {
+	return &jobTracker{active: map[string]bool{}}
+}
+
+func (t *jobTracker) add(sid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active[sid] = true
+}
+
+func (t *jobTracker) remove(sid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.active, sid)
+}
+
+// cancelAll cancels every tracked job concurrently, each bounded by timeout,
+// and returns the SIDs that were and were not cancelled, sorted.
+func (t *jobTracker) cancelAll(client *splunk.Client, timeout time.Duration) (cancelled, failed []string) {
+	t.mu.Lock()
+	sids := make([]string, 0, len(t.active))
+	for sid := range t.active {
+		sids = append(sids, sid)
+	}
+	t.mu.Unlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, sid := range sids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			err := client.CancelSearchContext(ctx, sid)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, sid)
+			} else {
+				cancelled = append(cancelled, sid)
+				t.remove(sid)
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Strings(cancelled)
+	sort.Strings(failed)
+	return cancelled, failed
+}
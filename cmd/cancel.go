@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"splunk_cli/splunk"
+)
+
+// cancelFlags holds the flags of the cancel command.
+type cancelFlags struct {
+	jobFlags *sidFlags
+	label    *string
+	all      *bool
+}
+
+// addCancelFlags defines the flags of the cancel command.
+func addCancelFlags(fs *flag.FlagSet) *cancelFlags {
+	return &cancelFlags{
+		jobFlags: addSIDFlags(fs),
+		label:    fs.String("label", "", "Cancel the running job with this label (custom.label, or the saved search name) instead of a SID"),
+		all:      fs.Bool("all", false, "With --label, cancel every matching job instead of failing when several match"),
+	}
+}
+
+// cancelCmd cancels a running search job, given by SID or by label.
+func cancelCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("cancel", "cancel")
+	flags := addCancelFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	var sid string
+	if *flags.label != "" {
+		if isFlagSet(fs, "sid") || isFlagSet(fs, "sid-file") {
+			return errors.New("--label cannot be used with --sid or --sid-file")
+		}
+	} else {
+		if *flags.all {
+			return errors.New("--all requires --label")
+		}
+		var err error
+		if sid, err = flags.jobFlags.resolve("cancel"); err != nil {
+			return err
+		}
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	if *flags.label != "" {
+		return cancelByLabel(client, *flags.label, *flags.all)
+	}
+	return client.CancelSearch(sid)
+}
+
+// cancelByLabel cancels the running jobs labeled label. More than one match
+// is an error unless all is set, so that a reused label cannot cancel other
+// people's searches by surprise.
+func cancelByLabel(client *splunk.Client, label string, all bool) error {
+	jobs, err := client.ListJobs(0)
+	if err != nil {
+		return err
+	}
+	var matches []splunk.Job
+	for _, job := range jobs {
+		if job.Label == label && !job.IsDone {
+			matches = append(matches, job)
+		}
+	}
+	if len(matches) == 0 {
+		return withExitCode(exitCodeJobNotFound, "no running job has the label '%s'", label)
+	}
+	if len(matches) > 1 && !all {
+		fmt.Fprintf(os.Stderr, "Warning: %d running jobs have the label '%s':\n", len(matches), label)
+		for _, job := range matches {
+			fmt.Fprintf(os.Stderr, "  %s (%s)\n", job.SID, job.DispatchState)
+		}
+		return errors.New("refusing to cancel more than one job; use --all to cancel them all, or --sid to pick one")
+	}
+
+	failures := 0
+	for _, job := range matches {
+		client.Log.Printf("Cancelling job %s\n", job.SID)
+		if err := client.CancelSearch(job.SID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", job.SID, err)
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d jobs could not be cancelled", failures, len(matches))
+	}
+	return nil
+}
@@ -2,31 +2,67 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"splunk_cli/splunk"
 
 	"golang.org/x/term"
 )
 
+// newCommandFlagSet creates the FlagSet for a subcommand. Its usage output, shown
+// for -h/--help and on parse errors, is the curated help from printHelp, so
+// 'splunk-cli run --help' matches 'splunk-cli help run'.
+func newCommandFlagSet(name, helpTopic string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		printHelp([]string{helpTopic})
+	}
+	return fs
+}
+
 // addCommonFlags defines flags common to all subcommands.
 func addCommonFlags(fs *flag.FlagSet, cfg *splunk.Config) {
 	fs.StringVar(&cfg.Host, "host", cfg.Host, "Splunk server URL (or use SPLUNK_HOST env var)")
 	fs.StringVar(&cfg.Token, "token", cfg.Token, "Splunk authentication token (or use SPLUNK_TOKEN env var)")
+	fs.StringVar(&cfg.TokenCommand, "token-command", cfg.TokenCommand, "Shell command that prints the authentication token, run when no token is set (e.g. a secrets manager CLI)")
 	fs.StringVar(&cfg.User, "user", cfg.User, "Splunk username (or use SPLUNK_USER env var)")
 	fs.StringVar(&cfg.Password, "password", cfg.Password, "Splunk password (or use SPLUNK_PASSWORD env var)")
 	fs.StringVar(&cfg.App, "app", cfg.App, "App context for the search (or use SPLUNK_APP env var)")
 	fs.BoolVar(&cfg.Insecure, "insecure", cfg.Insecure, "Skip TLS certificate verification")
+	fs.BoolVar(&cfg.SkipHostnameVerify, "skip-hostname-verify", cfg.SkipHostnameVerify, "Verify the TLS certificate chain but accept a certificate issued for a different host name")
 	fs.DurationVar(&cfg.HTTPTimeout, "http-timeout", cfg.HTTPTimeout, "Timeout for individual HTTP requests (e.g., '5s', '1m')")
 	fs.BoolVar(&cfg.Debug, "debug", false, "Enable verbose debug logging")
 	fs.IntVar(&cfg.Limit, "limit", cfg.Limit, "Maximum number of results to return (0 for all)")
+	fs.IntVar(&cfg.Retries, "retries", cfg.Retries, "Number of times to retry requests that are rate limited (429) or hit transient gateway errors")
+	fs.StringVar(&cfg.UserAgent, "user-agent", cfg.UserAgent, "User-Agent header sent with every request")
+	fs.IntVar(&cfg.MaxIdleConns, "max-idle-conns", cfg.MaxIdleConns, "Maximum idle connections kept open to the Splunk host (0 for the default of 10)")
+	fs.IntVar(&cfg.MaxConnsPerHost, "max-conns-per-host", cfg.MaxConnsPerHost, "Maximum concurrent connections to the Splunk host (0 for no limit)")
+	fs.Var(headerFlag{&cfg.Headers}, "header", "Extra header sent with every request as name=value; can be repeated")
+	fs.Var((*listFlag)(&cfg.SensitiveHeaders), "sensitive-header", "Comma-separated header names whose values are masked in debug output; can be repeated")
+	fs.Float64Var(&cfg.PollJitter, "poll-jitter", splunk.DefaultPollJitter, "Randomize each job status poll interval by up to this fraction either way (0 for fixed intervals), so runs started together do not poll in lockstep")
+	fs.StringVar(&cfg.RequestID, "request-id", cfg.RequestID, "Fixed X-Request-ID header value for all requests (random UUID per request if omitted)")
+}
+
+// isFlagSet reports whether the named flag was explicitly given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
 }
 
 // getChoiceFromTTY reads a single line of input from the terminal, bypassing stdin.
@@ -49,10 +85,7 @@ func getChoiceFromTTY() string {
 }
 
 func printDebugConfig(cfg *splunk.Config, log *splunk.Logger) {
-	maskedToken := ""
-	if len(cfg.Token) > 8 {
-		maskedToken = "toke..." + cfg.Token[len(cfg.Token)-4:]
-	}
+	maskedToken := splunk.MaskSecret(cfg.Token)
 	maskedPassword := ""
 	if cfg.Password != "" {
 		maskedPassword = "********"
@@ -60,14 +93,50 @@ func printDebugConfig(cfg *splunk.Config, log *splunk.Logger) {
 	log.Debugf("Final configuration:")
 	log.Debugf("  Host: %s", cfg.Host)
 	log.Debugf("  Token: %s", maskedToken)
+	log.Debugf("  Token Command: %s", cfg.TokenCommand)
 	log.Debugf("  User: %s", cfg.User)
 	log.Debugf("  Password: %s", maskedPassword)
 	log.Debugf("  App: %s", cfg.App)
 	log.Debugf("  Insecure: %t", cfg.Insecure)
+	log.Debugf("  Skip Hostname Verify: %t", cfg.SkipHostnameVerify)
 	log.Debugf("  HTTP Timeout: %s", cfg.HTTPTimeout)
+	log.Debugf("  Retries: %d", cfg.Retries)
+	log.Debugf("  User-Agent: %s", cfg.UserAgent)
+	log.Debugf("  Max Idle Conns: %d", cfg.MaxIdleConns)
+	log.Debugf("  Max Conns Per Host: %d", cfg.MaxConnsPerHost)
+	log.Debugf("  Poll Jitter: %g", cfg.PollJitter)
+	log.Debugf("  Default Earliest: %s", cfg.DefaultEarliest)
+	log.Debugf("  Default Latest: %s", cfg.DefaultLatest)
+	for _, name := range sortedHeaderNames(cfg.Headers) {
+		value := cfg.Headers[name]
+		if splunk.IsSensitiveHeader(name, cfg.SensitiveHeaders) {
+			value = splunk.MaskSecret(value)
+		}
+		log.Debugf("  Header %s: %s", name, value)
+	}
+	log.Debugf("  Request ID: %s", cfg.RequestID)
 }
 
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tokenCommandTimeout bounds how long --token-command may take to print a token.
+const tokenCommandTimeout = time.Minute
+
 func promptForCredentials(cfg *splunk.Config) error {
+	if cfg.Token == "" && cfg.TokenCommand != "" {
+		token, err := runTokenCommand(cfg.TokenCommand)
+		if err != nil {
+			return err
+		}
+		cfg.Token = token
+	}
 	if cfg.Token != "" || (cfg.User != "" && cfg.Password != "") {
 		return nil
 	}
@@ -93,14 +162,418 @@ func promptForCredentials(cfg *splunk.Config) error {
 	return nil
 }
 
-// getSplQuery determines the SPL query from either the --spl flag or --file flag.
-func getSplQuery(splFlag, fileFlag string) (string, error) {
-	if splFlag != "" && fileFlag != "" {
-		return "", errors.New("--spl and --file flags cannot be used at the same time")
+// runTokenCommand runs command through the shell and returns its trimmed
+// output as the token. The command's stderr is passed through, so a secrets
+// tool can report problems or ask for confirmation.
+func runTokenCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("token command did not finish within %v", tokenCommandTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %w", err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("token command printed no token")
+	}
+	return token, nil
+}
+
+// searchFlags holds the flags that describe a search to dispatch.
+type searchFlags struct {
+	fs               *flag.FlagSet
+	spl              *string
+	splLines         lineFlag
+	file             *string
+	earliest         *string
+	latest           *string
+	last             *string
+	autoCancel       *int
+	autoPause        *int
+	printURL         *bool
+	webHost          *string
+	sidFile          *string
+	label            *string
+	custom           keyValueFlag
+	params           keyValueFlag
+	sort             listFlag
+	timeFormat       *string
+	reuse            *time.Duration
+	showWindow       *bool
+	compress         *bool
+	likeSID          *string
+	noSpawn          *bool
+	statusBuckets    *int
+	sample           *int
+	preflight        *bool
+	allowDestructive *bool
+}
+
+// addSearchFlags defines the search dispatch flags shared by run and start.
+func addSearchFlags(fs *flag.FlagSet) *searchFlags {
+	sf := &searchFlags{
+		fs:               fs,
+		spl:              fs.String("spl", "", "SPL query to execute (cannot be used with --file)"),
+		file:             fs.String("file", "", "Read SPL query from a file (use '-' for stdin)"),
+		earliest:         fs.String("earliest", "", "Search earliest time (e.g., -1h, @d, 1672531200)"),
+		latest:           fs.String("latest", "", "Search latest time (e.g., now, @d, 1672617600)"),
+		last:             fs.String("last", "", lastFlagUsage),
+		autoCancel:       fs.Int("auto-cancel", 0, "Cancel the job after this many seconds of inactivity (0 to leave unset)"),
+		autoPause:        fs.Int("auto-pause", 0, "Pause the job after this many seconds of inactivity (0 to leave unset)"),
+		printURL:         fs.Bool("print-url", false, "Print the Splunk Web job inspector URL to stderr after the job starts"),
+		webHost:          fs.String("web-host", "", "Splunk Web base URL for --print-url (default: --host with port 8089 replaced by 8000)"),
+		sidFile:          fs.String("sid-file", "", "Write the SID of the new job to this file for use with --sid-file on later commands"),
+		label:            fs.String("label", "", "Tag the job with a recognizable label (sets custom.label)"),
+		timeFormat:       fs.String("time-format", "", "strptime-style format of --earliest/--latest when they are formatted times (epoch values are detected automatically)"),
+		reuse:            fs.Duration("reuse", 0, "Reuse an identical job dispatched within this period (e.g. '5m') instead of starting a new one"),
+		showWindow:       fs.Bool("show-window", false, "Print the absolute time window Splunk resolved for the search to stderr"),
+		preflight:        fs.Bool("preflight", false, "Check the user's capabilities before dispatch and warn if the search is likely to be rejected"),
+		allowDestructive: fs.Bool("allow-destructive", false, "Allow searches that modify indexed data ('| delete', '| collect')"),
+		noSpawn:          fs.Bool("no-spawn", false, "Run the search without a separate search process (spawn_process=false); for light '| rest' or '| inputlookup' searches"),
+		statusBuckets:    fs.Int("status-buckets", 0, "Number of timeline buckets Splunk keeps for the job, needed by 'timeline' (0 to leave unset)"),
+		sample:           fs.Int("sample", 0, "Stop the search after about this many events (auto_finalize_ec) for fast approximate results (0 for no limit)"),
+		likeSID:          fs.String("like-sid", "", "Search the same time window that Splunk resolved for this earlier job"),
+		compress:         fs.Bool("compress-request", false, "Gzip the job-creation request when it is 64 KiB or larger (falls back to uncompressed on HTTP 415)"),
+	}
+	fs.StringVar(sf.file, "f", "", "Shorthand for --file")
+	fs.Var(&sf.splLines, "spl-line", splLineFlagUsage)
+	fs.Var(&sf.custom, "custom", "Attach custom job metadata as key=value (sets custom.<key>); can be repeated")
+	fs.Var(&sf.sort, "sort", "Sort the results on the server by field[:asc|desc] (appends '| sort' to the SPL); comma-separated or repeated for several keys")
+	fs.Var(&sf.params, "param", "Extra job-creation parameter as key=value (e.g. max_time=60); can be repeated")
+	return sf
+}
+
+// query returns the SPL given via --spl or --file, with the '| sort' for
+// --sort appended.
+func (sf *searchFlags) query() (string, error) {
+	spl, err := getSplQuery(*sf.spl, *sf.file, sf.splLines)
+	if err != nil {
+		return "", err
+	}
+	if err := checkDestructive(spl, *sf.allowDestructive); err != nil {
+		return "", err
+	}
+	if len(sf.sort) == 0 {
+		return spl, nil
+	}
+	clause, err := sortClause(sf.sort)
+	if err != nil {
+		return "", err
+	}
+	if cmd := lastCommand(spl); transformingCommands[cmd] {
+		fmt.Fprintf(os.Stderr, "Warning: the search ends with the transforming command '%s'; --sort applies to its output table\n", cmd)
+	}
+	return strings.TrimRight(spl, " \t\r\n") + " " + clause, nil
+}
+
+// options returns the dispatch options for StartSearch. The time range comes
+// from --earliest/--latest or --last, falling back to the configured default.
+func (sf *searchFlags) options(cfg *splunk.Config) (splunk.SearchOptions, error) {
+	if *sf.likeSID != "" {
+		for _, name := range []string{"earliest", "latest", "last", "time-format"} {
+			if isFlagSet(sf.fs, name) {
+				return splunk.SearchOptions{}, fmt.Errorf("--like-sid cannot be used with --%s", name)
+			}
+		}
+	}
+	earliest, latest, err := resolveTimeRange(sf.fs, *sf.earliest, *sf.latest, *sf.last, cfg)
+	if err != nil {
+		return splunk.SearchOptions{}, err
+	}
+	if *sf.autoCancel < 0 || *sf.autoPause < 0 {
+		return splunk.SearchOptions{}, errors.New("--auto-cancel and --auto-pause must be >= 0")
+	}
+	if *sf.reuse < 0 || (*sf.reuse > 0 && *sf.reuse < time.Second) {
+		return splunk.SearchOptions{}, errors.New("--reuse must be 0 or at least 1s")
+	}
+	if *sf.statusBuckets < 0 {
+		return splunk.SearchOptions{}, errors.New("--status-buckets must be >= 0")
+	}
+	if *sf.sample < 0 || (isFlagSet(sf.fs, "sample") && *sf.sample == 0) {
+		return splunk.SearchOptions{}, errors.New("--sample must be > 0")
+	}
+	return splunk.SearchOptions{
+		Earliest:        earliest,
+		Latest:          latest,
+		AutoCancel:      *sf.autoCancel,
+		AutoPause:       *sf.autoPause,
+		Label:           *sf.label,
+		Custom:          sf.custom.toMap(),
+		TimeFormat:      *sf.timeFormat,
+		ReuseMaxAge:     *sf.reuse,
+		Params:          sf.params.toMap(),
+		CompressRequest: *sf.compress,
+		NoSpawn:         *sf.noSpawn,
+		StatusBuckets:   *sf.statusBuckets,
+		SampleEvents:    *sf.sample,
+	}, nil
+}
+
+// preflightCheck runs the capability pre-check for spl if --preflight was given.
+func (sf *searchFlags) preflightCheck(client *splunk.Client, spl string) {
+	if *sf.preflight {
+		preflightCheck(client, spl)
+	}
+}
+
+// applyLikeSID replaces the time range of opts with the window of the job
+// given by --like-sid, if any.
+func (sf *searchFlags) applyLikeSID(client *splunk.Client, opts *splunk.SearchOptions) error {
+	if *sf.likeSID == "" {
+		return nil
+	}
+	earliest, latest, err := jobWindow(client, *sf.likeSID)
+	if err != nil {
+		return err
+	}
+	client.Log.Printf("Using the time window of job %s: earliest=%s latest=%s\n", *sf.likeSID, earliest, latest)
+	opts.Earliest, opts.Latest = earliest, latest
+	return nil
+}
+
+// printJobURL prints the Splunk Web job inspector URL to stderr if --print-url was given.
+func (sf *searchFlags) printJobURL(cfg *splunk.Config, sid string) {
+	if !*sf.printURL {
+		return
+	}
+	jobURL, err := splunk.JobInspectorURL(cfg.Host, *sf.webHost, cfg.App, sid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not build job URL: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Job URL: %s\n", jobURL)
+}
+
+// searchWindowTimeout bounds how long --show-window waits for Splunk to resolve
+// the time range of a new job.
+const searchWindowTimeout = 5 * time.Second
+
+// printSearchWindow prints the absolute time window of the job to stderr if
+// --show-window was given. The window is read from the job's earliestTime and
+// latestTime once Splunk has parsed the search.
+func (sf *searchFlags) printSearchWindow(client *splunk.Client, sid string) {
+	if !*sf.showWindow {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), searchWindowTimeout)
+	defer cancel()
+	earliest, latest, err := client.SearchWindow(ctx, sid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not resolve the search window: %v\n", err)
+		return
+	}
+	if earliest == "" {
+		earliest = "(unbounded)"
+	}
+	if latest == "" {
+		latest = "(unbounded)"
+	}
+	fmt.Fprintf(os.Stderr, "Search window: %s to %s\n", earliest, latest)
+}
+
+// keyValueFlag is a repeatable flag of key=value pairs.
+type keyValueFlag []keyValue
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+func (f *keyValueFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, kv := range *f {
+		parts[i] = kv.key + "=" + kv.value
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *keyValueFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got '%s'", s)
+	}
+	*f = append(*f, keyValue{key: key, value: value})
+	return nil
+}
+
+// toMap returns the pairs as a map; later occurrences of a key win.
+func (f keyValueFlag) toMap() map[string]string {
+	if len(f) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(f))
+	for _, kv := range f {
+		m[kv.key] = kv.value
+	}
+	return m
+}
+
+// headerFlag is a repeatable name=value flag that adds to a header map,
+// overriding any header of the same name from the config file.
+type headerFlag struct {
+	headers *map[string]string
+}
+
+func (f headerFlag) String() string {
+	if f.headers == nil {
+		return ""
+	}
+	return strings.Join(sortedHeaderNames(*f.headers), ",")
+}
+
+func (f headerFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got '%s'", s)
+	}
+	if *f.headers == nil {
+		*f.headers = map[string]string{}
+	}
+	for existing := range *f.headers {
+		if strings.EqualFold(existing, name) {
+			delete(*f.headers, existing)
+		}
+	}
+	(*f.headers)[name] = value
+	return nil
+}
+
+// lineFlag is a repeatable flag whose values are kept verbatim, one per
+// occurrence.
+type lineFlag []string
+
+func (f *lineFlag) String() string {
+	return strings.Join(*f, "\n")
+}
+
+func (f *lineFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// listFlag is a repeatable flag of comma-separated values.
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f = append(*f, v)
+		}
+	}
+	return nil
+}
+
+// fieldsFlag is a repeatable list of field names like listFlag. A value of
+// the form @path names a file of field names instead: one or more per line,
+// separated by commas, with blank lines and lines starting with '#' skipped.
+type fieldsFlag []string
+
+func (f *fieldsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fieldsFlag) Set(s string) error {
+	path, ok := strings.CutPrefix(s, "@")
+	if !ok {
+		return (*listFlag)(f).Set(s)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read fields file: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		(*listFlag)(f).Set(line)
+	}
+	return nil
+}
+
+// sidFlags holds the flags that identify an existing job.
+type sidFlags struct {
+	sid     *string
+	sidFile *string
+}
+
+// addSIDFlags defines --sid and --sid-file for commands that act on an existing job.
+func addSIDFlags(fs *flag.FlagSet) *sidFlags {
+	return &sidFlags{
+		sid:     fs.String("sid", "", "Search ID (SID) of the job"),
+		sidFile: fs.String("sid-file", "", "Read the SID from this file (as written by 'start --sid-file')"),
+	}
+}
+
+// resolve returns the SID given via --sid or read from --sid-file.
+func (f *sidFlags) resolve(cmdName string) (string, error) {
+	if *f.sid != "" && *f.sidFile != "" {
+		return "", errors.New("--sid and --sid-file cannot be used at the same time")
+	}
+	if *f.sidFile != "" {
+		data, err := os.ReadFile(*f.sidFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read SID from file '%s': %w", *f.sidFile, err)
+		}
+		sid := strings.TrimSpace(string(data))
+		if sid == "" {
+			return "", fmt.Errorf("SID file '%s' is empty", *f.sidFile)
+		}
+		return sid, nil
+	}
+	if *f.sid == "" {
+		return "", fmt.Errorf("--sid or --sid-file is a required argument for '%s'", cmdName)
+	}
+	return *f.sid, nil
+}
+
+// writeSIDFile saves a SID to path so later commands can pick it up with --sid-file.
+func writeSIDFile(path, sid string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(sid+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write SID file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// splLineFlagUsage is the help text of the --spl-line flag.
+const splLineFlagUsage = "One line of the SPL query; repeat to build a multi-line query (cannot be used with --spl or --file)"
+
+// getSplQuery determines the SPL query from the --spl, --spl-line, or --file flag.
+// The --spl-line values are joined with newlines.
+func getSplQuery(splFlag, fileFlag string, lines []string) (string, error) {
+	given := 0
+	for _, set := range []bool{splFlag != "", fileFlag != "", len(lines) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		return "", errors.New("only one of --spl, --spl-line, and --file can be used")
 	}
 	if splFlag != "" {
 		return splFlag, nil
 	}
+	if len(lines) > 0 {
+		return strings.Join(lines, "\n"), nil
+	}
 	if fileFlag != "" {
 		var splBytes []byte
 		var err error
@@ -112,7 +585,39 @@ func getSplQuery(splFlag, fileFlag string) (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("failed to read SPL from file '%s': %w", fileFlag, err)
 		}
-		return string(splBytes), nil
+		return normalizeSPLText(splBytes), nil
+	}
+	return "", errors.New("--spl, --spl-line, or --file flag is required")
+}
+
+// utf8BOM is the byte order mark some Windows editors put at the start of
+// UTF-8 files.
+const utf8BOM = "\ufeff"
+
+// normalizeSPLText strips a leading UTF-8 byte order mark from SPL read from a
+// file and converts CRLF line endings to LF. Splunk would otherwise see the
+// BOM as part of the first search term and fail to parse the query.
+func normalizeSPLText(data []byte) string {
+	text := strings.TrimPrefix(string(data), utf8BOM)
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
+
+// checkPartialResults inspects the final messages of a job and returns an
+// error if they indicate that some search peers failed, unless allowPartial is
+// set. The matching messages are printed to stderr either way.
+func checkPartialResults(messages []splunk.SplunkMessage, allowPartial bool) error {
+	partial := splunk.PartialResultMessages(messages)
+	if len(partial) == 0 {
+		return nil
+	}
+	for _, msg := range partial {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", msg.Type, msg.Text)
+	}
+	if allowPartial {
+		return nil
+	}
+	if peers := splunk.FailedPeers(partial); len(peers) > 0 {
+		return fmt.Errorf("results may be incomplete (%d peers failed: %s)", len(peers), strings.Join(peers, ", "))
 	}
-	return "", errors.New("--spl or --file flag is required")
+	return fmt.Errorf("results may be incomplete (%d partial-results warnings, failed peers not named)", len(partial))
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+func TestPrintDebugConfigMasksSecrets(t *testing.T) {
+	for _, n := range []int{1, 4, 8, 12, 40} {
+		token := strings.Repeat("t", n-1) + "Z"
+		sessionKey := strings.Repeat("k", n-1) + "Q"
+		cfg := splunk.Config{
+			Host:     "https://splunk.example.com:8089",
+			Token:    token,
+			Password: "hunter2",
+			Debug:    true,
+			Headers:  map[string]string{"Authorization": "Splunk " + sessionKey},
+		}
+		client, err := splunk.NewClient(&cfg, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var log bytes.Buffer
+		client.Log.Out = &log
+		printDebugConfig(&cfg, client.Log)
+
+		out := log.String()
+		if strings.Contains(out, token) {
+			t.Errorf("%d-character token appears in debug config:\n%s", n, out)
+		}
+		if strings.Contains(out, sessionKey) {
+			t.Errorf("%d-character session key appears in debug config:\n%s", n, out)
+		}
+		if strings.Contains(out, "hunter2") {
+			t.Errorf("password appears in debug config:\n%s", out)
+		}
+		if !strings.Contains(out, "Token: "+splunk.MaskSecret(token)) {
+			t.Errorf("%d-character token not shown masked:\n%s", n, out)
+		}
+	}
+}
+
+func TestGetSplQueryNormalizesFiles(t *testing.T) {
+	const want = "index=main\n| stats count by host\n"
+	tests := map[string]string{
+		"plain":      "index=main\n| stats count by host\n",
+		"BOM":        "\ufeffindex=main\n| stats count by host\n",
+		"CRLF":       "index=main\r\n| stats count by host\r\n",
+		"BOM + CRLF": "\ufeffindex=main\r\n| stats count by host\r\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := normalizeSPLText([]byte(content)); got != want {
+				t.Errorf("normalizeSPLText = %q, want %q", got, want)
+			}
+			path := filepath.Join(t.TempDir(), "query.spl")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := getSplQuery("", path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("getSplQuery = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGetSplQueryKeepsInlineSPL(t *testing.T) {
+	// Only files are normalized; --spl is passed through as given.
+	spl := "index=main\r\n| head 1"
+	got, err := getSplQuery(spl, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != spl {
+		t.Errorf("getSplQuery = %q, want %q", got, spl)
+	}
+}
+
+func TestReadBatchQueriesSkipsBOM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.txt")
+	if err := os.WriteFile(path, []byte("\ufeffindex=a | head 1\r\n# comment\r\nindex=b\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readBatchQueries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"index=a | head 1", "index=b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("readBatchQueries = %q, want %q", got, want)
+	}
+}
+
+func TestCheckPartialResults(t *testing.T) {
+	messages := []splunk.SplunkMessage{
+		{Type: "WARN", Text: "Search results might be incomplete: the search process on peer idx1 ended prematurely."},
+		{Type: "WARN", Text: "Unable to distribute to peer named idx2 at uri=idx2:8089."},
+		{Type: "INFO", Text: "Your timerange was substituted based on your search string"},
+	}
+	var err error
+	_, stderr := captureOutput(t, func() { err = checkPartialResults(messages, false) })
+	if err == nil || !strings.Contains(err.Error(), "(2 peers failed: idx1, idx2)") {
+		t.Errorf("err = %v, want 2 failed peers idx1 and idx2", err)
+	}
+	if strings.Count(stderr, "Warning: WARN:") != 2 {
+		t.Errorf("partial messages not printed to stderr:\n%s", stderr)
+	}
+	captureOutput(t, func() { err = checkPartialResults(messages, true) })
+	if err != nil {
+		t.Errorf("with allowPartial: err = %v", err)
+	}
+	if err := checkPartialResults(messages[2:], false); err != nil {
+		t.Errorf("INFO message only: err = %v", err)
+	}
+	unnamed := []splunk.SplunkMessage{{Type: "WARN", Text: "Search results may be incomplete."}}
+	captureOutput(t, func() { err = checkPartialResults(unnamed, false) })
+	if err == nil || strings.Contains(err.Error(), "peers failed") {
+		t.Errorf("no peer named: err = %v, want no failed peer count", err)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// countFlags holds the flags that check or print only the result count of a
+// job, for monitoring checks.
+type countFlags struct {
+	fs        *flag.FlagSet
+	min       *int
+	max       *int
+	countOnly *bool
+}
+
+// addCountFlags defines --assert-count-min, --assert-count-max, and
+// --count-only.
+func addCountFlags(fs *flag.FlagSet) *countFlags {
+	return &countFlags{
+		fs:        fs,
+		min:       fs.Int("assert-count-min", 0, "Exit with status 6 if the job has fewer results than this"),
+		max:       fs.Int("assert-count-max", 0, "Exit with status 6 if the job has more results than this"),
+		countOnly: fs.Bool("count-only", false, "Print only the number of results instead of fetching them"),
+	}
+}
+
+// validate checks that the bounds, when given, are consistent.
+func (cf *countFlags) validate() error {
+	if *cf.min < 0 || *cf.max < 0 {
+		return errors.New("--assert-count-min and --assert-count-max must be >= 0")
+	}
+	if isFlagSet(cf.fs, "assert-count-min") && isFlagSet(cf.fs, "assert-count-max") && *cf.min > *cf.max {
+		return errors.New("--assert-count-min must not be greater than --assert-count-max")
+	}
+	return nil
+}
+
+// check compares the final result count of a job with the bounds that were
+// given and returns an error with exitCodeAssertion if it is out of range.
+func (cf *countFlags) check(resultCount int) error {
+	if isFlagSet(cf.fs, "assert-count-min") && resultCount < *cf.min {
+		return withExitCode(exitCodeAssertion, "result count %d is below the minimum of %d", resultCount, *cf.min)
+	}
+	if isFlagSet(cf.fs, "assert-count-max") && resultCount > *cf.max {
+		return withExitCode(exitCodeAssertion, "result count %d is above the maximum of %d", resultCount, *cf.max)
+	}
+	return nil
+}
+
+// printResults prints the results of the finished job sid, or with
+// --count-only just its result count, which needs no results fetch.
+func (cf *countFlags) printResults(client *splunk.Client, sid string, status *splunk.JobStatus, limit int, timeout time.Duration, out *outputFlags) error {
+	if !*cf.countOnly {
+		return printJobResults(client, sid, status, 0, limit, timeout, out)
+	}
+	fmt.Println(status.ResultCount)
+	if *out.failOnEmpty && status.ResultCount == 0 {
+		return withExitCode(exitCodeNoResults, "search returned no results")
+	}
+	return nil
+}
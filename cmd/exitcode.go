@@ -0,0 +1,41 @@
+package cmd
+
+import "fmt"
+
+// Exit codes other than the generic failure (1), so scripts can distinguish
+// specific outcomes without parsing output.
+const (
+	exitCodeError     = 1
+	exitCodeNoResults = 3
+	// exitCodeJobNotFound is used when a job does not exist (anymore).
+	exitCodeJobNotFound = 4
+	// exitCodePartial is used when 'run --require-done-within' finalized the
+	// job early and printed the results it had so far.
+	exitCodePartial = 5
+	// exitCodeAssertion is used when --assert-count-min/--assert-count-max
+	// find the result count out of range.
+	exitCodeAssertion = 6
+	// exitCodeBrokenPipe is used when stdout was closed by the reader (e.g.
+	// '| head'). It matches the status of a process killed by SIGPIPE, which
+	// is how the Go runtime exits on such a write where it can.
+	exitCodeBrokenPipe = 141
+)
+
+// exitError is an error that carries a specific process exit code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode wraps err so that the process exits with code.
+func withExitCode(code int, format string, a ...any) error {
+	return &exitError{code: code, err: fmt.Errorf(format, a...)}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"generic", errors.New("boom"), exitCodeError},
+		{"no results", withExitCode(exitCodeNoResults, "search returned no results"), exitCodeNoResults},
+		{"job not found", fmt.Errorf("failed: %w", &splunk.JobNotFoundError{SID: "x", APIError: &splunk.APIError{StatusCode: 404}}), exitCodeJobNotFound},
+		{"broken pipe", fmt.Errorf("write: %w", syscall.EPIPE), exitCodeBrokenPipe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeFor(tt.err); got != tt.want {
+				t.Errorf("exitCodeFor(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunBrokenPipe closes the reading end of stdout after the first kilobyte,
+// as '| head -c 1024' does, and checks that run stops with exit status 141.
+func TestRunBrokenPipe(t *testing.T) {
+	rows := make([]map[string]any, 20000)
+	for i := range rows {
+		rows[i] = map[string]any{"n": fmt.Sprint(i), "host": fmt.Sprintf("web%02d.example.com", i%50)}
+	}
+	fake := newFakeSplunk(t, []string{"n", "host"}, rows)
+
+	for _, format := range []string{"ndjson", "json", "csv", "table"} {
+		t.Run(format, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				io.ReadFull(r, make([]byte, 1024))
+				r.Close()
+			}()
+
+			origOut := os.Stdout
+			os.Stdout = w
+			var runErr error
+			func() {
+				defer func() { os.Stdout = origOut }()
+				defer func() {
+					if p := recover(); p != nil {
+						t.Fatalf("run panicked on a broken pipe: %v", p)
+					}
+				}()
+				runErr = runCmd([]string{"--host", fake.URL, "--token", "zzzzzzzz", "--spl", "index=main", "--silent", "--format", format}, splunk.Config{})
+			}()
+			w.Close()
+			<-done
+
+			if got := exitCodeFor(runErr); got != exitCodeBrokenPipe {
+				t.Errorf("exit code = %d (err %v), want %d", got, runErr, exitCodeBrokenPipe)
+			}
+		})
+	}
+}
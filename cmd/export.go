@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"splunk_cli/output"
+	"splunk_cli/splunk"
+)
+
+// exportFlags holds the flags of the export command.
+type exportFlags struct {
+	spl              *string
+	file             *string
+	splLines         lineFlag
+	earliest         *string
+	latest           *string
+	last             *string
+	timeFormat       *string
+	params           keyValueFlag
+	allowDestructive *bool
+	format           *string
+	timeout          *time.Duration
+}
+
+// addExportFlags defines the flags of the export command.
+func addExportFlags(fs *flag.FlagSet) *exportFlags {
+	flags := &exportFlags{
+		spl:              fs.String("spl", "", "SPL query to export (cannot be used with --file)"),
+		file:             fs.String("file", "", "Read SPL query from a file (use '-' for stdin)"),
+		earliest:         fs.String("earliest", "", "Search earliest time (e.g., -1h, @d, 1672531200)"),
+		latest:           fs.String("latest", "", "Search latest time (e.g., now, @d, 1672617600)"),
+		last:             fs.String("last", "", lastFlagUsage),
+		timeFormat:       fs.String("time-format", "", "strptime-style format of --earliest/--latest when they are formatted times (epoch values are detected automatically)"),
+		allowDestructive: fs.Bool("allow-destructive", false, "Allow searches that modify indexed data ('| delete', '| collect')"),
+		format:           fs.String("format", "ndjson", "Output format: 'ndjson' (one JSON object per line, printed as it arrives) or 'json' (single document)"),
+		timeout:          fs.Duration("timeout", 10*time.Minute, "Timeout for the whole export"),
+	}
+	fs.StringVar(flags.file, "f", "", "Shorthand for --file")
+	fs.Var(&flags.splLines, "spl-line", splLineFlagUsage)
+	fs.Var(&flags.params, "param", "Extra export parameter as key=value (e.g. max_time=60); can be repeated")
+	return flags
+}
+
+// exportCmd runs a search through Splunk's export endpoint and prints the
+// results as they stream in, without creating a job to poll. With --format
+// ndjson each row is printed as soon as it arrives; with json the rows are
+// collected into a single {"results": [...]} document.
+func exportCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("export", "export")
+	flags := addExportFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if *flags.format != "ndjson" && *flags.format != "json" {
+		return fmt.Errorf("invalid --format '%s': must be 'ndjson' or 'json'", *flags.format)
+	}
+	if *flags.timeout <= 0 {
+		return errors.New("--timeout must be > 0")
+	}
+	query, err := getSplQuery(*flags.spl, *flags.file, flags.splLines)
+	if err != nil {
+		return err
+	}
+	if err := checkDestructive(query, *flags.allowDestructive); err != nil {
+		return err
+	}
+	opts := splunk.SearchOptions{TimeFormat: *flags.timeFormat, Params: flags.params.toMap()}
+	if opts.Earliest, opts.Latest, err = resolveTimeRange(fs, *flags.earliest, *flags.latest, *flags.last, &baseCfg); err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	// The export is a single long response, which the per-request HTTP
+	// timeout would cut off; --timeout bounds it instead.
+	baseCfg.HTTPTimeout = *flags.timeout
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var rows []json.RawMessage
+	emit := func(row json.RawMessage) error {
+		if *flags.format == "json" {
+			rows = append(rows, row)
+			return nil
+		}
+		var line bytes.Buffer
+		if err := json.Compact(&line, row); err != nil {
+			return fmt.Errorf("failed to encode result row: %w", err)
+		}
+		line.WriteByte('\n')
+		_, err := os.Stdout.Write(line.Bytes())
+		return err
+	}
+	count, err := client.Export(ctx, query, opts, emit)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("export was interrupted after %d rows", count)
+		}
+		return err
+	}
+	client.Log.Printf("Exported %d results.\n", count)
+
+	if *flags.format == "json" {
+		rw := output.NewJSONWriter(os.Stdout, useCompactJSON(fs, false))
+		return writeRows(rw, rows, columnPolicy{})
+	}
+	return nil
+}
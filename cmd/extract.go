@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// extractValue resolves the JSON Pointer (RFC 6901) against the result
+// document {"results": [...]} and writes the value to w: scalars bare (strings
+// without quotes), objects and arrays as compact JSON.
+func extractValue(rows []json.RawMessage, pointer string, w io.Writer) error {
+	results := make([]any, len(rows))
+	for i, raw := range rows {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&results[i]); err != nil {
+			return fmt.Errorf("failed to decode result row: %w", err)
+		}
+	}
+	value, err := resolvePointer(map[string]any{"results": results}, pointer)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		_, err = fmt.Fprintln(w, v)
+	case json.Number:
+		_, err = fmt.Fprintln(w, v.String())
+	default:
+		var encoded []byte
+		if encoded, err = json.Marshal(v); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(encoded))
+	}
+	return err
+}
+
+// resolvePointer returns the value the JSON Pointer refers to within doc.
+func resolvePointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid --extract '%s': a JSON Pointer must start with '/' (e.g. '/results/0/count')", pointer)
+	}
+	current := doc
+	path := ""
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		path += "/" + token
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("--extract '%s' does not resolve: no field '%s' at '%s'", pointer, token, path)
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) || (len(token) > 1 && token[0] == '0') {
+				return nil, fmt.Errorf("--extract '%s' does not resolve: no element '%s' at '%s' (%d elements)", pointer, token, path, len(v))
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("--extract '%s' does not resolve: '%s' is not an object or array", pointer, strings.TrimSuffix(path, "/"+token))
+		}
+	}
+	return current, nil
+}
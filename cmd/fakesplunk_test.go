@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSplunk is a minimal Splunk REST API for command tests. Every search
+// creates the job "fake.1", which is done at once and has rows as its results.
+type fakeSplunk struct {
+	*httptest.Server
+	rows     []map[string]any
+	fields   []string
+	messages []map[string]string
+	// dispatchState is the job's final state; empty means DONE.
+	dispatchState string
+
+	mu       sync.Mutex
+	requests []string // "METHOD path" of every request
+}
+
+func newFakeSplunk(t *testing.T, fields []string, rows []map[string]any) *fakeSplunk {
+	t.Helper()
+	f := &fakeSplunk{rows: rows, fields: fields}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeSplunk) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	f.mu.Unlock()
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == "POST" && path == "/services/search/jobs":
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"sid": "fake.1"})
+	case r.Method == "POST" && strings.HasSuffix(path, "/control"):
+		json.NewEncoder(w).Encode(map[string]any{})
+	case strings.HasSuffix(path, "/results"):
+		f.serveResults(w, r)
+	case strings.HasPrefix(path, "/services/search/jobs/"):
+		state := f.dispatchState
+		if state == "" {
+			state = "DONE"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"entry": []any{map[string]any{"content": map[string]any{
+			"isDone":        true,
+			"dispatchState": state,
+			"resultCount":   len(f.rows),
+			"eventCount":    len(f.rows),
+			"messages":      f.messages,
+		}}}})
+	default:
+		json.NewEncoder(w).Encode(map[string]any{"entry": []any{map[string]any{"content": map[string]any{"username": "admin"}}}})
+	}
+}
+
+func (f *fakeSplunk) serveResults(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	count, _ := strconv.Atoi(q.Get("count"))
+	end := len(f.rows)
+	if count > 0 && offset+count < end {
+		end = offset + count
+	}
+	page := f.rows[min(offset, len(f.rows)):end]
+	if q.Get("output_mode") == "csv" {
+		cw := csv.NewWriter(w)
+		cw.Write(f.fields)
+		for _, row := range page {
+			record := make([]string, len(f.fields))
+			for i, name := range f.fields {
+				if v, ok := row[name]; ok {
+					record[i] = v.(string)
+				}
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"results": page})
+}
+
+// captureOutput runs fn with os.Stdout and os.Stderr redirected to separate
+// files and returns what was written to each.
+func captureOutput(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origOut, origErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	defer func() { os.Stdout, os.Stderr = origOut, origErr }()
+	fn()
+	outFile.Close()
+	errFile.Close()
+
+	outBytes, _ := os.ReadFile(outFile.Name())
+	errBytes, _ := os.ReadFile(errFile.Name())
+	return string(outBytes), string(errBytes)
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// fanOutHostField is the field added to every row of a fan-out run to name the
+// host it came from.
+const fanOutHostField = "_host"
+
+// fanOutResult is the outcome of the search on one host of a fan-out run.
+type fanOutResult struct {
+	host string
+	rows []json.RawMessage
+	// partial are the job's messages saying that some search peers failed,
+	// so the rows may be incomplete.
+	partial []splunk.SplunkMessage
+	err     error
+}
+
+// runFanOut runs the same search on every host concurrently, at most
+// concurrency at a time, and prints the results of all hosts merged into one
+// result set, each row labeled with a _host field. A host that fails is
+// reported on stderr without affecting the others; the command still fails
+// afterwards so scripts notice. So does a host whose results may be incomplete
+// because search peers failed, unless --allow-partial is given; its rows are
+// still merged.
+//
+// On Ctrl-C or SIGTERM, onSignal is applied to every running job without
+// prompting: they are cancelled, or left running with 'detach'.
+func runFanOut(hosts []string, concurrency int, spl string, opts splunk.SearchOptions, baseCfg splunk.Config, silent bool, timeout, resultsTimeout time.Duration, onSignal string, out *outputFlags) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	results := make([]fanOutResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = fanOutResult{host: host, err: errors.New("not started: interrupted")}
+				return
+			}
+			cfg := baseCfg
+			cfg.Host = host
+			results[i] = runFanOutHost(ctx, &cfg, silent, spl, opts, timeout, resultsTimeout, onSignal, out)
+		}()
+	}
+	wg.Wait()
+
+	var rows []json.RawMessage
+	failures, incomplete := 0, 0
+	for _, r := range results {
+		if r.err != nil {
+			failures++
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", r.host, r.err)
+			continue
+		}
+		if len(r.partial) > 0 {
+			incomplete++
+			for _, msg := range r.partial {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s: %s\n", r.host, msg.Type, msg.Text)
+			}
+		}
+		for _, row := range r.rows {
+			labeled, err := withField(row, fanOutHostField, r.host)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, labeled)
+		}
+	}
+
+	w, err := out.open()
+	if err != nil {
+		return err
+	}
+	w = newIntervalWriter(w, *out.flushInterval)
+	count, err := writeResultRows(ctx, rows, w, out)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if *out.printFields {
+		fmt.Fprintf(os.Stderr, "Fields (%d): %s\n", len(out.seenFields), strings.Join(out.seenFields, ", "))
+	}
+	if ctx.Err() != nil {
+		return errors.New("fan-out was interrupted")
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d hosts failed", failures, len(hosts))
+	}
+	if incomplete > 0 && !*out.allowPartial {
+		return fmt.Errorf("results may be incomplete on %d of %d hosts", incomplete, len(hosts))
+	}
+	if *out.failOnEmpty && count == 0 {
+		return withExitCode(exitCodeNoResults, "search returned no results")
+	}
+	return nil
+}
+
+// runFanOutHost runs the search on the host in cfg and fetches its results.
+// When ctx is cancelled, a job that is still running is cancelled on the host,
+// or left running if onSignal is 'detach'. The results fetch is bounded by
+// resultsTimeout (0 for no limit).
+func runFanOutHost(ctx context.Context, cfg *splunk.Config, silent bool, spl string, opts splunk.SearchOptions, timeout, resultsTimeout time.Duration, onSignal string, out *outputFlags) fanOutResult {
+	result := fanOutResult{host: cfg.Host}
+	client, err := splunk.NewClient(cfg, true)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	sid, _, err := client.StartSearch(spl, opts)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if !silent {
+		fmt.Fprintf(os.Stderr, "%s: job started with SID %s\n", cfg.Host, sid)
+	}
+
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	status, err := client.WaitForJobStatus(waitCtx, sid)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("job %s did not finish within %v", sid, timeout)
+		} else if errors.Is(err, context.Canceled) && onSignal == signalActionDetach {
+			err = fmt.Errorf("detached from job %s; it is still running", sid)
+		} else if errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("job %s was interrupted", sid)
+			cancelCtx, cancelCancel := context.WithTimeout(context.Background(), batchCancelTimeout)
+			defer cancelCancel()
+			if cancelErr := client.CancelSearchContext(cancelCtx, sid); cancelErr != nil {
+				err = fmt.Errorf("job %s was interrupted and could not be cancelled: %w", sid, cancelErr)
+			}
+		}
+		result.err = err
+		return result
+	}
+	result.partial = splunk.PartialResultMessages(status.Messages)
+
+	fetchCtx := ctx
+	if resultsTimeout > 0 {
+		var fetchCancel context.CancelFunc
+		fetchCtx, fetchCancel = context.WithTimeout(ctx, resultsTimeout)
+		defer fetchCancel()
+	}
+	result.rows, result.err = client.Results(fetchCtx, sid, out.resultsOptions(0, cfg.Limit))
+	if errors.Is(result.err, context.DeadlineExceeded) {
+		result.err = fmt.Errorf("fetching the results of job %s timed out after %v", sid, resultsTimeout)
+	}
+	if result.err == nil && !silent {
+		fmt.Fprintf(os.Stderr, "%s: %d results\n", cfg.Host, len(result.rows))
+	}
+	return result
+}
+
+// withField returns row with key set to value as its first field. An existing
+// field of the same name is replaced.
+func withField(row json.RawMessage, key, value string) (json.RawMessage, error) {
+	row = bytes.TrimSpace(row)
+	fields, err := orderedKeys(row)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f == key {
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal(row, &m); err != nil {
+				return nil, fmt.Errorf("failed to decode result row: %w", err)
+			}
+			delete(m, key)
+			if row, err = json.Marshal(m); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	labeled := append([]byte{'{'}, k...)
+	labeled = append(labeled, ':')
+	labeled = append(labeled, v...)
+	if rest := strings.TrimSpace(string(row[1:])); rest != "}" {
+		labeled = append(labeled, ',')
+	}
+	return append(labeled, row[1:]...), nil
+}
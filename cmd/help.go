@@ -12,13 +12,30 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "Usage: splunk-cli [global options] <command> [options]")
 	fmt.Fprintln(os.Stderr, "\nA flexible CLI tool to interact with the Splunk REST API.")
 	fmt.Fprintln(os.Stderr, "\nGlobal Options:")
-	fmt.Fprintln(os.Stderr, "  --config <path>  Path to a custom configuration file")
-	fmt.Fprintln(os.Stderr, "  --version        Print version information and exit")
+	fmt.Fprintln(os.Stderr, "  --config <path>      Path to a custom configuration file")
+	fmt.Fprintln(os.Stderr, "  --config-dir <path>  Base directory for config and state files (or use SPLUNK_CONFIG_DIR env var)")
+	fmt.Fprintln(os.Stderr, "  --config-json <json> Config settings as a JSON object, merged over the config file")
+	fmt.Fprintln(os.Stderr, "  --version            Print version information and exit")
 	fmt.Fprintln(os.Stderr, "\nCommands:")
 	fmt.Fprintln(os.Stderr, "  run      Run a search job synchronously and wait for results.")
 	fmt.Fprintln(os.Stderr, "  start    Start a search job and print the SID immediately.")
 	fmt.Fprintln(os.Stderr, "  status   Check the status of a running search job.")
 	fmt.Fprintln(os.Stderr, "  results  Get the results of a completed search job.")
+	fmt.Fprintln(os.Stderr, "  batch    Run several searches concurrently and print their results as NDJSON.")
+	fmt.Fprintln(os.Stderr, "  jobs     List search jobs.")
+	fmt.Fprintln(os.Stderr, "  recover  List jobs you detached from with Ctrl+C during 'run'.")
+	fmt.Fprintln(os.Stderr, "  inspect  Show a job's performance breakdown.")
+	fmt.Fprintln(os.Stderr, "  timeline Show when a job's events happened as a text histogram.")
+	fmt.Fprintln(os.Stderr, "  export   Stream the results of a search without creating a job.")
+	fmt.Fprintln(os.Stderr, "  cancel   Cancel a running search job.")
+	fmt.Fprintln(os.Stderr, "  wait     Wait for an existing (e.g. detached) job and print its results.")
+	fmt.Fprintln(os.Stderr, "  ping     Check connectivity and authentication.")
+	fmt.Fprintln(os.Stderr, "  whoami   Show the authenticated user, roles, and default app.")
+	fmt.Fprintln(os.Stderr, "  metrics  Show the CPU, memory, and search load of the Splunk server.")
+	fmt.Fprintln(os.Stderr, "  saved    Create a (scheduled) saved search (saved create).")
+	fmt.Fprintln(os.Stderr, "  macros   List search macros (macros list).")
+	fmt.Fprintln(os.Stderr, "  request  Send a raw request to any REST endpoint.")
+	fmt.Fprintln(os.Stderr, "  repl     Run searches interactively in one authenticated session.")
 	fmt.Fprintln(os.Stderr, "  help     Show help for a specific command.")
 	fmt.Fprintln(os.Stderr, "\nUse 'splunk-cli help <command>' for more information about a specific command.")
 }
@@ -31,41 +48,79 @@ func printHelp(args []string) {
 	cmd := args[0]
 	var fs *flag.FlagSet
 	dummyCfg := splunk.Config{}
+	localOnly := false // Commands that never talk to Splunk take no connection flags
 
 	// Create a global FlagSet to include --config and --version for help output
 	globalFs := flag.NewFlagSet("global", flag.ContinueOnError)
 	globalFs.String("config", "", "Path to a custom configuration file")
+	globalFs.String("config-dir", "", "Base directory for config and state files (or use SPLUNK_CONFIG_DIR env var)")
+	globalFs.String("config-json", "", "Config settings as a JSON object in the config file format, merged over the config file")
 	globalFs.Bool("version", false, "Print version information and exit") // Also include version here for consistency
 
+	// Each command's flags are defined by the same function the command uses,
+	// so the help cannot drift from the flags actually accepted.
 	switch cmd {
 	case "run":
-		fs = flag.NewFlagSet("run", flag.ExitOnError)
-		fs.String("spl", "", "SPL query to execute (cannot be used with --file)")
-		fs.String("file", "", "Read SPL from a file ('-' for stdin)")
-		fs.String("f", "", "Shorthand for --file")
-		fs.String("earliest", "", "Search earliest time")
-		fs.String("latest", "", "Search latest time")
-		fs.Duration("timeout", 0, "Timeout for the run command")
-		fs.Bool("silent", false, "Suppress progress messages")
+		fs = flag.NewFlagSet("run", flag.ContinueOnError)
+		addRunFlags(fs)
 	case "start":
-		fs = flag.NewFlagSet("start", flag.ExitOnError)
-		fs.String("spl", "", "SPL query to execute (cannot be used with --file)")
-		fs.String("file", "", "Read SPL from a file ('-' for stdin)")
-		fs.String("f", "", "Shorthand for --file")
-		fs.String("earliest", "", "Search earliest time")
-		fs.String("latest", "", "Search latest time")
-		fs.Bool("silent", false, "Suppress progress messages")
+		fs = flag.NewFlagSet("start", flag.ContinueOnError)
+		addStartFlags(fs)
 	case "status":
 		fs = flag.NewFlagSet("status", flag.ContinueOnError)
-		fs.String("sid", "", "Search ID (SID) of the job")
+		addStatusFlags(fs)
 	case "results":
 		fs = flag.NewFlagSet("results", flag.ContinueOnError)
-		fs.String("sid", "", "Search ID (SID) of the job")
+		addResultsFlags(fs)
+	case "batch":
+		fs = flag.NewFlagSet("batch", flag.ContinueOnError)
+		addBatchFlags(fs)
+	case "jobs":
+		fs = flag.NewFlagSet("jobs", flag.ContinueOnError)
+	case "inspect":
+		fs = flag.NewFlagSet("inspect", flag.ContinueOnError)
+		addInspectFlags(fs)
+	case "timeline":
+		fs = flag.NewFlagSet("timeline", flag.ContinueOnError)
+		addTimelineFlags(fs)
+	case "export":
+		fs = flag.NewFlagSet("export", flag.ContinueOnError)
+		addExportFlags(fs)
+	case "cancel":
+		fs = flag.NewFlagSet("cancel", flag.ContinueOnError)
+		addCancelFlags(fs)
+	case "wait":
+		fs = flag.NewFlagSet("wait", flag.ContinueOnError)
+		addWaitFlags(fs)
+	case "ping":
+		fs = flag.NewFlagSet("ping", flag.ContinueOnError)
+	case "whoami":
+		fs = flag.NewFlagSet("whoami", flag.ContinueOnError)
+	case "metrics":
+		fs = flag.NewFlagSet("metrics", flag.ContinueOnError)
+		addMetricsFlags(fs)
+	case "saved":
+		fs = flag.NewFlagSet("saved create", flag.ContinueOnError)
+		addSavedFlags(fs)
+	case "macros":
+		fs = flag.NewFlagSet("macros list", flag.ContinueOnError)
+	case "request":
+		fs = flag.NewFlagSet("request", flag.ContinueOnError)
+		addRequestFlags(fs)
+	case "repl":
+		fs = flag.NewFlagSet("repl", flag.ContinueOnError)
+		addReplFlags(fs)
+	case "recover":
+		fs = flag.NewFlagSet("recover", flag.ContinueOnError)
+		addRecoverFlags(fs)
+		localOnly = true
 	default:
 		fmt.Fprintf(os.Stderr, "Error: Unknown command for help: %s", cmd)
 		return
 	}
-	addCommonFlags(fs, &dummyCfg)
+	if !localOnly {
+		addCommonFlags(fs, &dummyCfg)
+	}
 	fmt.Fprintf(os.Stderr, "Usage: splunk-cli %s [options]\n\nOptions for %s:\n", cmd, cmd)
 	fs.PrintDefaults()
 	fmt.Fprintln(os.Stderr, "\nGlobal Options:") // Print global options after command-specific ones
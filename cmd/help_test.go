@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestHelpShowsCommandFlags checks help output for flags and defaults that
+// used to differ from what the commands accept.
+func TestHelpShowsCommandFlags(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"results", []string{"-silent\n", "-timeout duration\n    \tWith --wait, maximum time to wait for the job (0 for no limit) (default 10m0s)"}},
+		{"run", []string{"-timeout duration\n    \tTimeout for the search job to complete (default 10m0s)", "-hosts value"}},
+		{"wait", []string{"-timeout duration\n    \tTotal timeout for waiting on the job (0 for no limit) (default 10m0s)"}},
+		{"status", []string{"-timeout duration\n    \tWith --wait, maximum time to wait for the job (0 for no limit) (default 10m0s)"}},
+		{"batch", []string{"-timeout duration\n    \tTimeout for each search job to complete (default 10m0s)"}},
+		{"export", []string{"-timeout duration\n    \tTimeout for the whole export (default 10m0s)"}},
+		{"start", []string{"-silent\n    \tSuppress progress messages (default true)"}},
+		{"saved", []string{"Dispatch earliest time (e.g., -24h@h)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			_, stderr := captureOutput(t, func() { printHelp([]string{tt.command}) })
+			for _, want := range tt.want {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("help %s lacks %q:\n%s", tt.command, want, stderr)
+				}
+			}
+		})
+	}
+}
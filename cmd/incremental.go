@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+const incrementalStateFile = "incremental.json"
+
+// incrementalFlags holds the flags of checkpointed 'run --incremental' pulls,
+// where each run starts at the latest time of the previous successful one.
+type incrementalFlags struct {
+	fs      *flag.FlagSet
+	enabled *bool
+	key     *string
+}
+
+// addIncrementalFlags defines --incremental and --state-key.
+func addIncrementalFlags(fs *flag.FlagSet) *incrementalFlags {
+	return &incrementalFlags{
+		fs:      fs,
+		enabled: fs.Bool("incremental", false, "Start the search where the last successful run with the same --state-key ended, and record where this one ends"),
+		key:     fs.String("state-key", "", "Name of the checkpoint used by --incremental"),
+	}
+}
+
+// validate checks that --incremental and --state-key are given together and
+// that no other option decides the earliest time.
+func (inc *incrementalFlags) validate() error {
+	if *inc.enabled != (*inc.key != "") {
+		return errors.New("--incremental and --state-key must be used together")
+	}
+	if !*inc.enabled {
+		return nil
+	}
+	for _, name := range []string{"like-sid", "time-format"} {
+		if isFlagSet(inc.fs, name) {
+			return fmt.Errorf("--%s cannot be used with --incremental", name)
+		}
+	}
+	return nil
+}
+
+// apply sets the earliest time of opts to the stored checkpoint. On the first
+// run there is none, and the time range from the other flags is kept.
+func (inc *incrementalFlags) apply(cfg *splunk.Config, log *splunk.Logger, opts *splunk.SearchOptions) error {
+	if !*inc.enabled {
+		return nil
+	}
+	cp, err := loadCheckpoint(cfg.ConfigDir, *inc.key)
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		earliest := opts.Earliest
+		if earliest == "" {
+			earliest = "(all time)"
+		}
+		log.Printf("No checkpoint for state key '%s' yet; starting from earliest=%s\n", *inc.key, earliest)
+		return nil
+	}
+	log.Printf("Continuing from the checkpoint of state key '%s': earliest=%s\n", *inc.key, cp.Latest)
+	opts.Earliest = cp.Latest
+	return nil
+}
+
+// save records the latest time Splunk resolved for job sid as the checkpoint
+// for the next run.
+func (inc *incrementalFlags) save(client *splunk.Client, cfg *splunk.Config, sid string) error {
+	if !*inc.enabled {
+		return nil
+	}
+	_, latest, err := jobWindow(client, sid)
+	if err != nil {
+		return fmt.Errorf("checkpoint not updated: %w", err)
+	}
+	if latest == "" {
+		return fmt.Errorf("checkpoint not updated: job %s has no latest time", sid)
+	}
+	if err := saveCheckpoint(cfg.ConfigDir, *inc.key, latest, sid); err != nil {
+		return fmt.Errorf("checkpoint not updated: %w", err)
+	}
+	client.Log.Printf("Checkpoint for state key '%s' set to latest=%s\n", *inc.key, latest)
+	return nil
+}
+
+// checkpoint is where the last successful 'run --incremental' with a given
+// --state-key left off.
+type checkpoint struct {
+	// Latest is the resolved latest time of the job, in epoch seconds; the
+	// next run starts there.
+	Latest    string `json:"latest"`
+	SID       string `json:"sid"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// loadCheckpoints reads all checkpoints from the state file in configDir,
+// keyed by --state-key. A missing file holds no checkpoints.
+func loadCheckpoints(configDir string) (map[string]checkpoint, error) {
+	checkpoints := map[string]checkpoint{}
+	data, err := os.ReadFile(filepath.Join(configDir, incrementalStateFile))
+	if os.IsNotExist(err) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read incremental state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("could not parse incremental state file %s: %w", filepath.Join(configDir, incrementalStateFile), err)
+	}
+	return checkpoints, nil
+}
+
+// loadCheckpoint returns the checkpoint stored for key, or nil on the first
+// run.
+func loadCheckpoint(configDir, key string) (*checkpoint, error) {
+	checkpoints, err := loadCheckpoints(configDir)
+	if err != nil {
+		return nil, err
+	}
+	if cp, ok := checkpoints[key]; ok {
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+// saveCheckpoint stores the checkpoint for key, keeping those of other keys.
+// The state file is replaced atomically so an interrupted write cannot lose
+// every checkpoint.
+func saveCheckpoint(configDir, key, latest, sid string) error {
+	if configDir == "" {
+		return errors.New("no config directory available")
+	}
+	checkpoints, err := loadCheckpoints(configDir)
+	if err != nil {
+		return err
+	}
+	checkpoints[key] = checkpoint{
+		Latest:    latest,
+		SID:       sid,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return fmt.Errorf("could not create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(configDir, incrementalStateFile+".*")
+	if err != nil {
+		return fmt.Errorf("could not write incremental state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write incremental state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write incremental state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(configDir, incrementalStateFile)); err != nil {
+		return fmt.Errorf("could not write incremental state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"splunk_cli/splunk"
+)
+
+// inspectFlags holds the flags of the inspect command.
+type inspectFlags struct {
+	jobFlags *sidFlags
+	top      *int
+	format   *string
+}
+
+// addInspectFlags defines the flags of the inspect command.
+func addInspectFlags(fs *flag.FlagSet) *inspectFlags {
+	return &inspectFlags{
+		jobFlags: addSIDFlags(fs),
+		top:      fs.Int("top", 10, "Number of most time-consuming phases to print (0 for all)"),
+		format:   fs.String("format", "text", "Output format: 'text' or 'json'"),
+	}
+}
+
+// inspectCmd prints a job's performance breakdown, longest phases first.
+func inspectCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("inspect", "inspect")
+	flags := addInspectFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if *flags.format != "text" && *flags.format != "json" {
+		return fmt.Errorf("invalid --format '%s': must be 'text' or 'json'", *flags.format)
+	}
+	if *flags.top < 0 {
+		return errors.New("--top must be >= 0")
+	}
+	sid, err := flags.jobFlags.resolve("inspect")
+	if err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	inspection, err := client.JobInspect(sid)
+	if err != nil {
+		return err
+	}
+	if *flags.top > 0 && len(inspection.Phases) > *flags.top {
+		inspection.Phases = inspection.Phases[:*flags.top]
+	}
+
+	if *flags.format == "json" {
+		out, err := json.MarshalIndent(inspection, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal job inspection: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("SID: %s\n", inspection.SID)
+	fmt.Printf("Search: %s\n", strings.Join(strings.Fields(inspection.Search), " "))
+	fmt.Printf("DispatchState: %s\n", inspection.DispatchState)
+	fmt.Printf("RunDuration: %.3fs\n", inspection.RunDuration)
+	fmt.Printf("Events: %d scanned, %d matched, %d results\n", inspection.ScanCount, inspection.EventCount, inspection.ResultCount)
+	fmt.Printf("DiskUsage: %d bytes\n", inspection.DiskUsage)
+	if inspection.SearchLogPath != "" {
+		fmt.Printf("SearchLog: %s\n", inspection.SearchLogPath)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PHASE\tDURATION\tINVOCATIONS\tINPUT\tOUTPUT")
+	for _, p := range inspection.Phases {
+		fmt.Fprintf(w, "%s\t%.3fs\t%d\t%d\t%d\n", p.Name, p.DurationSecs, p.Invocations, p.InputCount, p.OutputCount)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"splunk_cli/splunk"
+
+	"golang.org/x/term"
+)
+
+// Actions taken on an in-flight job when the wait is interrupted.
+const (
+	signalActionCancel = "cancel"
+	signalActionDetach = "detach"
+)
+
+// addOnSignalFlag defines --on-signal, which selects what happens to the job on
+// SIGTERM, or on Ctrl-C when there is no terminal to prompt on.
+func addOnSignalFlag(fs *flag.FlagSet) *string {
+	return fs.String("on-signal", signalActionCancel, "Action on SIGTERM, or on Ctrl-C without a terminal: 'cancel' the job or 'detach' from it")
+}
+
+// validateOnSignal checks the value of --on-signal.
+func validateOnSignal(action string) error {
+	switch action {
+	case signalActionCancel, signalActionDetach:
+		return nil
+	default:
+		return fmt.Errorf("invalid --on-signal '%s': must be 'cancel' or 'detach'", action)
+	}
+}
+
+// waitForJobInteractive waits for a job to finish within timeout (0 for no
+// limit). On Ctrl-C at a
+// terminal the user is asked whether to cancel the job or detach from it. SIGTERM,
+// and Ctrl-C when no terminal is available, apply onSignal without prompting.
+// onDetach, if not nil, is called when the job is detached. It returns the final
+// job status only if the job finished and its results should be fetched; a nil
+// status with a nil error means the job was cancelled or detached. A job that
+// failed on the server is returned with its final status and a
+// *splunk.JobFailedError.
+func waitForJobInteractive(client *splunk.Client, sid string, timeout time.Duration, onSignal string, onDetach func(sid string)) (*splunk.JobStatus, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	type waitResult struct {
+		status *splunk.JobStatus
+		err    error
+	}
+	resultChan := make(chan waitResult, 1)
+	go func() {
+		status, err := client.WaitForJobStatus(ctx, sid)
+		resultChan <- waitResult{status, err}
+	}()
+
+	select {
+	case r := <-resultChan:
+		if errors.Is(r.err, context.DeadlineExceeded) {
+			return nil, &waitTimeoutError{timeout}
+		}
+		if r.err != nil {
+			return r.status, r.err
+		}
+		return r.status, nil
+	case sig := <-sigChan:
+		signal.Stop(sigChan)
+		stopSpinner(client)
+		action := onSignal
+		if sig == syscall.SIGINT && canPrompt() {
+			action = promptSignalAction()
+		} else {
+			fmt.Fprintf(os.Stderr, "\nReceived %s, applying --on-signal=%s.\n", sig, action)
+		}
+
+		if action == signalActionDetach {
+			fmt.Fprintf(os.Stderr, "Detaching from job %s. Use the 'wait' or 'results' command to fetch results later.\n", sid)
+			if onDetach != nil {
+				onDetach(sid)
+			}
+			return nil, nil
+		}
+		return nil, client.CancelSearch(sid)
+	}
+}
+
+// waitTimeoutError is returned by waitForJobInteractive when the job is not
+// done within the timeout.
+type waitTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *waitTimeoutError) Error() string {
+	return fmt.Sprintf("command timed out after %v", e.timeout)
+}
+
+// promptSignalAction asks the user whether to cancel or detach after Ctrl-C.
+// A second signal while the prompt is shown cancels the job.
+func promptSignalAction() string {
+	fmt.Fprintf(os.Stderr, "\n^C detected. What would you like to do?\n  (c)ancel the job on Splunk\n  (d)etach and let it run in the background\nChoice [c/d]: ")
+
+	choiceChan := make(chan string)
+	go func() {
+		choiceChan <- getChoiceFromTTY()
+	}()
+
+	secondSigChan := make(chan os.Signal, 1)
+	signal.Notify(secondSigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(secondSigChan)
+
+	select {
+	case choice := <-choiceChan:
+		if strings.ToLower(choice) == "d" {
+			return signalActionDetach
+		}
+	case <-secondSigChan:
+	}
+	return signalActionCancel
+}
+
+// canPrompt reports whether an interactive prompt can be shown and answered:
+// stderr must be a terminal, and so must the input getChoiceFromTTY reads from.
+func canPrompt() bool {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return term.IsTerminal(int(os.Stdin.Fd()))
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
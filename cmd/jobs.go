@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"splunk_cli/splunk"
+)
+
+// jobsCmd lists the search jobs visible to the authenticated user.
+func jobsCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("jobs", "jobs")
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	jobs, err := client.ListJobs(baseCfg.Limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SID\tSTATE\tRESULTS\tLABEL\tSEARCH")
+	for _, j := range jobs {
+		search := strings.Join(strings.Fields(j.Search), " ")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", j.SID, j.DispatchState, j.ResultCount, j.Label, search)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"splunk_cli/splunk"
+)
+
+func macrosCmd(args []string, baseCfg splunk.Config) error {
+	if len(args) == 0 || args[0] != "list" {
+		return errors.New("usage: splunk-cli macros list [options]")
+	}
+
+	fs := newCommandFlagSet("macros list", "macros")
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args[1:])
+
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	macros, err := client.ListMacros(baseCfg.Limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tAPP\tARGS\tDEFINITION")
+	for _, m := range macros {
+		definition := strings.Join(strings.Fields(m.Definition), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Name, m.App, m.Args, definition)
+	}
+	return w.Flush()
+}
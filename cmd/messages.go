@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// messagesFileFlagUsage is the help text of the --messages-file flag.
+const messagesFileFlagUsage = "Append the job's messages from Splunk to this file as JSON lines (time, SID, type, text)"
+
+// addMessagesFileFlag defines --messages-file, which keeps the messages Splunk
+// attaches to a job as an audit trail apart from the results and the log.
+func addMessagesFileFlag(fs *flag.FlagSet) *string {
+	return fs.String("messages-file", "", messagesFileFlagUsage)
+}
+
+// jobMessageRecord is one line of a --messages-file.
+type jobMessageRecord struct {
+	Time string `json:"time"`
+	SID  string `json:"sid"`
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// recordJobMessages appends the messages of job sid, taken from the final
+// status the caller acted on, to path, one JSON object per line, so that the
+// file collects the messages of many runs. Splunk does not timestamp its
+// messages; the time recorded is when the job finished. Nothing is done when
+// path is empty.
+func recordJobMessages(sid string, messages []splunk.SplunkMessage, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	var lines []byte
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, msg := range messages {
+		line, err := json.Marshal(jobMessageRecord{Time: now, SID: sid, Type: msg.Type, Text: msg.Text})
+		if err != nil {
+			return fmt.Errorf("failed to encode job message: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open messages file '%s': %w", path, err)
+	}
+	if _, err := f.Write(lines); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write messages file '%s': %w", path, err)
+	}
+	return f.Close()
+}
+
+// finalJobMessages returns the messages of a job from the outcome of waiting
+// for it: those of its final status, or, when only the failure is known, the
+// errors it failed with.
+func finalJobMessages(status *splunk.JobStatus, err error) []splunk.SplunkMessage {
+	if status != nil {
+		return status.Messages
+	}
+	var failed *splunk.JobFailedError
+	if errors.As(err, &failed) {
+		return failed.Messages
+	}
+	return nil
+}
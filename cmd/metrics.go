@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"splunk_cli/splunk"
+)
+
+// metricsFlags holds the flags of the metrics command.
+type metricsFlags struct {
+	format *string
+}
+
+// addMetricsFlags defines the flags of the metrics command.
+func addMetricsFlags(fs *flag.FlagSet) *metricsFlags {
+	return &metricsFlags{
+		format: fs.String("format", "table", "Output format: 'table' or 'json'"),
+	}
+}
+
+// metricsCmd prints the CPU, memory, and search load of the Splunk server.
+func metricsCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("metrics", "metrics")
+	flags := addMetricsFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if *flags.format != "table" && *flags.format != "json" {
+		return fmt.Errorf("invalid --format '%s': must be 'table' or 'json'", *flags.format)
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	metrics, err := client.ServerMetrics()
+	if err != nil {
+		return err
+	}
+
+	if *flags.format == "json" {
+		out, err := json.MarshalIndent(metrics, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal metrics: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tVALUE")
+	fmt.Fprintf(w, "CPU used\t%.1f%% (user %.1f%%, system %.1f%%, %d CPUs)\n",
+		100-metrics.CPUIdlePct, metrics.CPUUserPct, metrics.CPUSystemPct, metrics.CPUCount)
+	fmt.Fprintf(w, "Load (1m, per CPU)\t%.2f\n", metrics.LoadAvg1Min)
+	memory := fmt.Sprintf("%.0f MB of %.0f MB", metrics.MemUsedMB, metrics.MemTotalMB)
+	if metrics.MemTotalMB > 0 {
+		memory += fmt.Sprintf(" (%.1f%%)", 100*metrics.MemUsedMB/metrics.MemTotalMB)
+	}
+	fmt.Fprintf(w, "Memory used\t%s\n", memory)
+	searches := fmt.Sprintf("%d", metrics.RunningSearches)
+	if metrics.MaxHistoricalSearches > 0 {
+		searches += fmt.Sprintf(" (limit %d historical, %d real-time)", metrics.MaxHistoricalSearches, metrics.MaxRealtimeSearches)
+	}
+	fmt.Fprintf(w, "Searches running\t%s\n", searches)
+	return w.Flush()
+}
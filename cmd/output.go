@@ -0,0 +1,650 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
+
+	"splunk_cli/output"
+	"splunk_cli/splunk"
+
+	"golang.org/x/term"
+)
+
+const defaultPager = "less -R"
+
+// outputFlags holds the flags that control how job results are printed.
+type outputFlags struct {
+	fs               *flag.FlagSet
+	format           *string
+	pager            *bool
+	compact          *bool
+	expandMultivalue *bool
+	mvSeparator      *string
+	normalizeTime    *bool
+	timezone         *string
+	allowPartial     *bool
+	failOnEmpty      *bool
+	postProcess      *string
+	redact           listFlag
+	fields           fieldsFlag
+	hashFields       listFlag
+	flatten          *bool
+	flattenSep       *string
+	tableColumns     *string
+	events           *bool
+	segmentation     *string
+	printFields      *bool
+	extract          *string
+	flushInterval    *time.Duration
+	timeField        *string
+	throttle         *int
+	outputFile       *string
+	templateText     *string
+	maxFieldBytes    *int
+	rawPassthrough   *bool
+
+	location   *time.Location
+	template   *template.Template // parsed --output-template
+	seenFields []string           // field names written, collected for --print-fields
+}
+
+// addOutputFlags defines the result output flags shared by run, results, and wait.
+func addOutputFlags(fs *flag.FlagSet) *outputFlags {
+	o := &outputFlags{
+		fs:               fs,
+		format:           fs.String("format", "json", "Output format: 'json' (single document), 'ndjson' (one JSON object per line), 'csv', 'table', 'raw' (the _raw field of each row), or 'parquet' (requires --output)"),
+		outputFile:       fs.String("output", "", "Write the results to this file instead of stdout"),
+		templateText:     fs.String("output-template", "", "Print each result row with this Go template instead of --format (e.g. '{{.host}}: {{.count}} errors'); missing fields are empty"),
+		pager:            fs.Bool("pager", false, "Page results through $PAGER (default 'less -R') when stdout is a terminal"),
+		compact:          fs.Bool("compact", false, "Print compact single-line JSON (default: pretty on a terminal, compact when piped)"),
+		expandMultivalue: fs.Bool("expand-multivalue", false, "Expand multivalue fields into one row per value combination (or join them, see --mv-separator)"),
+		mvSeparator:      fs.String("mv-separator", "", "With --expand-multivalue, join multivalue fields with this separator instead of expanding rows"),
+		normalizeTime:    fs.Bool("normalize-time", false, "Rewrite the _time field as an RFC3339 timestamp (see --tz)"),
+		timezone:         fs.String("tz", "UTC", "Time zone for --normalize-time (e.g. 'UTC', 'Local', 'Asia/Tokyo')"),
+		allowPartial:     fs.Bool("allow-partial", false, "Do not fail when the job reports that some search peers failed"),
+		failOnEmpty:      fs.Bool("fail-on-empty", false, "Exit with status 3 when the search returns no results (the empty result set is still printed)"),
+		postProcess:      fs.String("postprocess", "", "Post-process SPL applied to the job's results on the server (e.g. '| stats count by host')"),
+		flatten:          fs.Bool("flatten", false, "Flatten nested objects and arrays in each result row into dotted key paths (e.g. 'a.b.c', 'arr.0')"),
+		flattenSep:       fs.String("flatten-sep", ".", "Separator used to join key paths with --flatten"),
+		events:           fs.Bool("events", false, "Fetch the job's events instead of its results (for non-transforming searches)"),
+		segmentation:     fs.String("segmentation", "none", "With --events, how _raw is segmented: 'none', 'raw', 'inner', 'outer', or 'full'"),
+		extract:          fs.String("extract", "", "Print only the value at this JSON Pointer into the result document (e.g. '/results/0/count'), unquoted for scalars"),
+		timeField:        fs.String("time-field", "_time", "Field moved to the first column of table, CSV, and JSON output when present ('' keeps Splunk's order)"),
+		flushInterval:    fs.Duration("flush-interval", 0, "Buffer output and flush it to stdout at this interval (e.g. '200ms'); 0 writes every row immediately"),
+		throttle:         fs.Int("throttle", 0, "Emit at most this many result rows per second, e.g. to feed a rate-limited API (0 for no limit)"),
+		maxFieldBytes:    fs.Int("max-field-bytes", 0, "With --format table or csv, shorten string values longer than this many bytes, noting their length; with --debug, also shorten longer lines of the debug log (0 for no limit)"),
+		rawPassthrough:   fs.Bool("raw-passthrough", false, "With --format json, copy Splunk's result rows to the output byte for byte as one compact document instead of decoding and re-encoding them"),
+		printFields:      fs.Bool("print-fields", false, "After the results, print the sorted names of all fields seen in them to stderr"),
+		tableColumns:     fs.String("table-columns", tableColumnsUnion, "Column policy for --format table: 'union' (keys of all rows, capped) or 'first' (keys of the first row)"),
+	}
+	fs.Var(&o.fields, "fields", "Comma-separated fields to keep in each result row, in this order, or @file to read them from a file; can be repeated")
+	fs.Var(&o.redact, "redact", "Comma-separated fields to remove from each result row; can be repeated")
+	fs.Var(&o.hashFields, "hash-field", "Comma-separated fields whose values are replaced with their SHA-256 hash; can be repeated")
+	return o
+}
+
+// validate checks the output flags before any request is made. debug is whether
+// debug logging is on.
+func (o *outputFlags) validate(debug bool) error {
+	switch *o.format {
+	case "json", "ndjson", "table", "raw":
+	case "csv":
+		if *o.expandMultivalue || *o.normalizeTime || len(o.redact) > 0 || len(o.hashFields) > 0 {
+			return errors.New("--expand-multivalue, --normalize-time, --redact, and --hash-field are only supported with JSON and NDJSON output")
+		}
+	case "parquet":
+		if *o.outputFile == "" {
+			return errors.New("--format parquet requires --output <file>")
+		}
+	default:
+		return fmt.Errorf("invalid --format '%s': must be 'json', 'ndjson', 'csv', 'table', 'raw', or 'parquet'", *o.format)
+	}
+	if *o.templateText != "" {
+		if isFlagSet(o.fs, "format") {
+			return errors.New("--output-template cannot be used with --format")
+		}
+		if *o.extract != "" {
+			return errors.New("--output-template cannot be used with --extract")
+		}
+		tmpl, err := output.ParseRowTemplate(*o.templateText)
+		if err != nil {
+			return fmt.Errorf("invalid --output-template: %w", err)
+		}
+		o.template = tmpl
+	}
+	if *o.outputFile != "" && *o.pager {
+		return errors.New("--pager cannot be used with --output")
+	}
+	if *o.tableColumns != tableColumnsUnion && *o.tableColumns != tableColumnsFirst {
+		return fmt.Errorf("invalid --table-columns '%s': must be 'union' or 'first'", *o.tableColumns)
+	}
+	if isFlagSet(o.fs, "segmentation") && !*o.events {
+		return errors.New("--segmentation requires --events")
+	}
+	if isFlagSet(o.fs, "table-columns") && *o.format != "table" {
+		return errors.New("--table-columns requires --format table")
+	}
+	if isFlagSet(o.fs, "flatten-sep") && !*o.flatten {
+		return errors.New("--flatten-sep requires --flatten")
+	}
+	if *o.extract != "" && isFlagSet(o.fs, "format") && *o.format != "json" {
+		return errors.New("--extract resolves against the JSON result document and cannot be used with --format other than 'json'")
+	}
+	if *o.mvSeparator != "" && !*o.expandMultivalue {
+		return errors.New("--mv-separator requires --expand-multivalue")
+	}
+	if *o.postProcess != "" && !strings.HasPrefix(strings.TrimSpace(*o.postProcess), "|") {
+		return errors.New("--postprocess must start with '|' (e.g. '| stats count by host')")
+	}
+	if *o.flushInterval < 0 {
+		return errors.New("--flush-interval must be >= 0")
+	}
+	if *o.throttle < 0 {
+		return errors.New("--throttle must be >= 0")
+	}
+	if *o.maxFieldBytes < 0 {
+		return errors.New("--max-field-bytes must be >= 0")
+	}
+	if *o.maxFieldBytes > 0 && *o.format != "table" && *o.format != "csv" && !debug {
+		return errors.New("--max-field-bytes only applies to --format table and csv, and to --debug output")
+	}
+	if *o.rawPassthrough {
+		if *o.format != "json" {
+			return errors.New("--raw-passthrough requires --format json")
+		}
+		// The rows are never decoded, so nothing that rewrites or inspects
+		// them can be applied.
+		for _, name := range []string{"output-template", "expand-multivalue", "normalize-time", "flatten", "fields", "redact", "hash-field", "extract", "print-fields", "throttle", "time-field"} {
+			if isFlagSet(o.fs, name) {
+				return fmt.Errorf("--raw-passthrough cannot be used with --%s", name)
+			}
+		}
+	}
+	loc, err := time.LoadLocation(*o.timezone)
+	if err != nil {
+		return fmt.Errorf("invalid --tz '%s': %w", *o.timezone, err)
+	}
+	o.location = loc
+	return nil
+}
+
+// transforms returns the row transforms selected by the output flags, in the
+// order they are applied: multivalue expansion, flattening, time normalization,
+// field projection, redaction, then hashing. Redaction and hashing come last so they also cover
+// expanded values and flattened key paths, and a field that is both redacted
+// and hashed is simply removed.
+func (o *outputFlags) transforms() []rowTransform {
+	var transforms []rowTransform
+	if *o.expandMultivalue {
+		transforms = append(transforms, expandMultivalue(*o.mvSeparator))
+	}
+	if *o.flatten {
+		transforms = append(transforms, flattenRow(*o.flattenSep))
+	}
+	if *o.normalizeTime {
+		transforms = append(transforms, normalizeTime("_time", o.location))
+	}
+	if len(o.fields) > 0 {
+		transforms = append(transforms, keepFields(o.fields))
+	}
+	if len(o.redact) > 0 {
+		transforms = append(transforms, redactFields(o.redact))
+	}
+	if len(o.hashFields) > 0 {
+		transforms = append(transforms, hashFields(o.hashFields))
+	}
+	return transforms
+}
+
+// printJobResults fetches the results of a finished job and writes them to stdout.
+// The fetch is bounded by timeout (0 for no limit) and can be interrupted with Ctrl-C.
+func printJobResults(client *splunk.Client, sid string, status *splunk.JobStatus, offset, limit int, timeout time.Duration, out *outputFlags) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	w, err := out.open()
+	if err != nil {
+		return err
+	}
+	w = newIntervalWriter(w, *out.flushInterval)
+	client.Log.Println("Fetching results...")
+	count, err := writeJobResults(ctx, client, sid, offset, limit, w, out)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("fetching results timed out after %v", timeout)
+	}
+	if errors.Is(err, context.Canceled) {
+		return errors.New("fetching results was interrupted")
+	}
+	if err != nil {
+		return err
+	}
+	if *out.printFields {
+		fmt.Fprintf(os.Stderr, "Fields (%d): %s\n", len(out.seenFields), strings.Join(out.seenFields, ", "))
+	}
+
+	if err := checkPartialResults(status.Messages, *out.allowPartial); err != nil {
+		return err
+	}
+	if *out.failOnEmpty && count == 0 {
+		return withExitCode(exitCodeNoResults, "search returned no results")
+	}
+	return nil
+}
+
+// resultsOptions returns the fetch options for the window starting at offset.
+func (o *outputFlags) resultsOptions(offset, limit int) splunk.ResultsOptions {
+	opts := splunk.ResultsOptions{
+		Offset:      offset,
+		Limit:       limit,
+		PostProcess: *o.postProcess,
+		Events:      *o.events,
+	}
+	if *o.events {
+		opts.Segmentation = *o.segmentation
+	}
+	opts.FirstColumn = *o.timeField
+	return opts
+}
+
+// writeJobResults fetches results in the selected format and writes them to w,
+// returning the number of result rows written.
+func writeJobResults(ctx context.Context, client *splunk.Client, sid string, offset, limit int, w io.Writer, out *outputFlags) (int, error) {
+	if *out.rawPassthrough {
+		return client.ResultsJSON(ctx, sid, out.resultsOptions(offset, limit), w)
+	}
+	if *out.format == "csv" && !*out.flatten && !*out.printFields && *out.extract == "" && *out.throttle == 0 && *out.maxFieldBytes == 0 && len(out.fields) == 0 {
+		// CSV is streamed page by page straight from Splunk's CSV output mode.
+		// --print-fields, --throttle, --max-field-bytes, and --fields need the
+		// decoded rows, so they take the path below.
+		return client.ResultsCSV(ctx, sid, out.resultsOptions(offset, limit), w)
+	}
+
+	sink := out.newResultSink(ctx, w)
+	if err := client.ForEachResult(ctx, sid, out.resultsOptions(offset, limit), sink.add); err != nil {
+		return sink.count, err
+	}
+	return sink.close()
+}
+
+// writeResultRows applies the selected transforms to rows and writes them to w
+// in the selected format, returning the number of rows written. A throttled
+// write stops early when ctx is done.
+func writeResultRows(ctx context.Context, rows []json.RawMessage, w io.Writer, out *outputFlags) (int, error) {
+	sink := out.newResultSink(ctx, w)
+	for _, raw := range rows {
+		if err := sink.add(raw); err != nil {
+			return sink.count, err
+		}
+	}
+	return sink.close()
+}
+
+// resultSink applies the selected transforms to result rows and writes them in
+// the selected format as they arrive, so a large result set is never held in
+// memory as a whole. Only --extract, which evaluates its path against the
+// complete document, and header fields that must cover every row keep rows
+// back.
+type resultSink struct {
+	out        *outputFlags
+	w          io.Writer
+	transforms []rowTransform
+	rows       *rowSink
+	extracted  []json.RawMessage // the rows for --extract
+	fields     map[string]bool   // the field names for --print-fields
+	count      int
+}
+
+func (o *outputFlags) newResultSink(ctx context.Context, w io.Writer) *resultSink {
+	s := &resultSink{out: o, w: w, transforms: o.transforms()}
+	if *o.printFields {
+		s.fields = map[string]bool{}
+	}
+	if *o.extract != "" {
+		return s
+	}
+	rw := o.newResultWriter(w)
+	if *o.throttle > 0 {
+		rw = newThrottledWriter(ctx, rw, *o.throttle)
+	}
+	if *o.maxFieldBytes > 0 && (*o.format == "table" || *o.format == "csv") {
+		rw = &truncatingWriter{ResultWriter: rw, max: *o.maxFieldBytes}
+	}
+	s.rows = newRowSink(rw, o.columnPolicy())
+	s.rows.growHeader = o.headerOrdersKeysOnly()
+	return s
+}
+
+// add transforms a single result row and writes the rows it becomes.
+func (s *resultSink) add(raw json.RawMessage) error {
+	rows, err := transformRow(raw, s.transforms)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if s.fields != nil {
+			keys, err := orderedKeys(row)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				s.fields[k] = true
+			}
+		}
+		s.count++
+		if s.rows == nil {
+			s.extracted = append(s.extracted, row)
+			continue
+		}
+		if err := s.rows.add(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close writes what is left, records the field names for --print-fields, and
+// returns the number of rows written.
+func (s *resultSink) close() (int, error) {
+	if s.fields != nil {
+		s.out.seenFields = slices.Sorted(maps.Keys(s.fields))
+	}
+	if s.rows == nil {
+		return s.count, extractValue(s.extracted, *s.out.extract, s.w)
+	}
+	return s.count, s.rows.close()
+}
+
+// headerOrdersKeysOnly reports whether the selected writer uses the header
+// fields only to order the keys of later rows, so the header can grow as rows
+// arrive instead of being chosen up front.
+func (o *outputFlags) headerOrdersKeysOnly() bool {
+	if o.template != nil {
+		return true
+	}
+	switch *o.format {
+	case "ndjson", "raw":
+		return true
+	case "csv", "table", "parquet":
+		return false
+	default:
+		return true // the JSON document
+	}
+}
+
+// newResultWriter returns the ResultWriter for the selected format.
+func (o *outputFlags) newResultWriter(w io.Writer) output.ResultWriter {
+	if o.template != nil {
+		return output.NewTemplateWriter(w, o.template)
+	}
+	switch *o.format {
+	case "ndjson":
+		return output.NewNDJSONWriter(w)
+	case "csv":
+		return output.NewCSVWriter(w)
+	case "table":
+		return output.NewTableWriter(w)
+	case "raw":
+		return output.NewRawWriter(w)
+	case "parquet":
+		return output.NewParquetWriter(w)
+	default:
+		compact := useCompactJSON(o.fs, *o.compact)
+		if *o.outputFile != "" && !isFlagSet(o.fs, "compact") {
+			compact = true // like piped output, a file gets compact JSON
+		}
+		return output.NewJSONWriter(w, compact)
+	}
+}
+
+// columnPolicy returns how the header fields are chosen for the selected format.
+func (o *outputFlags) columnPolicy() columnPolicy {
+	// CSV columns must cover every row; JSON only uses the fields for key order.
+	policy := columnPolicy{firstField: *o.timeField}
+	if len(o.fields) > 0 {
+		// A projection gives the columns and their order, including fields
+		// that no row has.
+		policy.columns = o.fields
+		return policy
+	}
+	if *o.format == "table" {
+		if *o.tableColumns == tableColumnsFirst {
+			policy.firstRowOnly = true
+		} else {
+			policy.scanRows = tableUnionScanRows
+		}
+	}
+	return policy
+}
+
+// useCompactJSON reports whether JSON output should be compact. An explicit
+// --compact flag wins; otherwise output is pretty on a terminal and compact when piped.
+func useCompactJSON(fs *flag.FlagSet, compact bool) bool {
+	if isFlagSet(fs, "compact") {
+		return compact
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// open returns the writer for the results: the --output file, or stdout
+// (through the pager with --pager).
+func (o *outputFlags) open() (io.WriteCloser, error) {
+	if *o.outputFile == "" {
+		return openOutput(os.Stdout, *o.pager)
+	}
+	f, err := os.Create(*o.outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, nil
+}
+
+// openOutput returns the destination for the result payload: f itself, or the
+// stdin of $PAGER when usePager is set and f is a terminal. It degrades to f
+// when no pager is available. Closing the returned writer waits for the pager.
+func openOutput(f *os.File, usePager bool) (io.WriteCloser, error) {
+	if !usePager || !term.IsTerminal(int(f.Fd())) {
+		return nopWriteCloser{f}, nil
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = strings.Fields(defaultPager)
+	}
+	path, err := exec.LookPath(pager[0])
+	if err != nil {
+		// No usable pager; degrade to plain output.
+		return nopWriteCloser{f}, nil
+	}
+
+	pagerCmd := exec.Command(path, pager[1:]...)
+	pagerCmd.Stdout = f
+	pagerCmd.Stderr = os.Stderr
+	stdin, err := pagerCmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("could not open pipe to pager: %w", err)
+	}
+	if err := pagerCmd.Start(); err != nil {
+		return nopWriteCloser{f}, nil
+	}
+	return &pagerWriter{cmd: pagerCmd, stdin: stdin}, nil
+}
+
+// intervalWriter buffers writes and flushes them to the underlying writer
+// every interval, so output appears promptly without a system call per row.
+// Close flushes what is left before closing the underlying writer.
+type intervalWriter struct {
+	mu     sync.Mutex
+	buf    *bufio.Writer
+	dst    io.WriteCloser
+	ticker *time.Ticker
+	done   chan struct{}
+	err    error // first error of a background flush
+}
+
+// newIntervalWriter wraps w in an intervalWriter. With an interval of 0, w is
+// returned unchanged.
+func newIntervalWriter(w io.WriteCloser, interval time.Duration) io.WriteCloser {
+	if interval <= 0 {
+		return w
+	}
+	iw := &intervalWriter{
+		buf:    bufio.NewWriterSize(w, 64*1024),
+		dst:    w,
+		ticker: time.NewTicker(interval),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case <-iw.ticker.C:
+				iw.mu.Lock()
+				if err := iw.buf.Flush(); err != nil && iw.err == nil {
+					iw.err = err
+				}
+				iw.mu.Unlock()
+			case <-iw.done:
+				return
+			}
+		}
+	}()
+	return iw
+}
+
+func (iw *intervalWriter) Write(b []byte) (int, error) {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	if iw.err != nil {
+		return 0, iw.err
+	}
+	return iw.buf.Write(b)
+}
+
+func (iw *intervalWriter) Close() error {
+	iw.ticker.Stop()
+	close(iw.done)
+	iw.mu.Lock()
+	err := iw.err
+	if flushErr := iw.buf.Flush(); err == nil {
+		err = flushErr
+	}
+	iw.mu.Unlock()
+	if closeErr := iw.dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// throttledWriter is a ResultWriter that passes at most one row per tick of
+// its ticker on to the wrapped writer, flushing writers that buffer rows so
+// each row leaves as it is released. Waiting for a tick ends when ctx is done,
+// so Ctrl-C is not delayed by the throttle.
+type throttledWriter struct {
+	output.ResultWriter
+	ctx    context.Context
+	ticker *time.Ticker
+}
+
+// newThrottledWriter wraps rw so it writes at most perSecond rows per second.
+func newThrottledWriter(ctx context.Context, rw output.ResultWriter, perSecond int) output.ResultWriter {
+	return &throttledWriter{
+		ResultWriter: rw,
+		ctx:          ctx,
+		ticker:       time.NewTicker(time.Second / time.Duration(perSecond)),
+	}
+}
+
+func (t *throttledWriter) WriteRow(row map[string]any) error {
+	select {
+	case <-t.ticker.C:
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	}
+	if err := t.ResultWriter.WriteRow(row); err != nil {
+		return err
+	}
+	if f, ok := t.ResultWriter.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (t *throttledWriter) Close() error {
+	t.ticker.Stop()
+	return t.ResultWriter.Close()
+}
+
+// truncatingWriter is a ResultWriter that shortens string values longer than
+// max bytes before passing rows on, so that huge fields such as a multi-megabyte
+// _raw do not swamp a table or CSV meant for reading.
+type truncatingWriter struct {
+	output.ResultWriter
+	max int
+}
+
+func (t *truncatingWriter) WriteRow(row map[string]any) error {
+	shortened := make(map[string]any, len(row))
+	for k, v := range row {
+		if s, ok := v.(string); ok && len(s) > t.max {
+			v = splunk.TruncateField(s, t.max)
+		}
+		shortened[k] = v
+	}
+	return t.ResultWriter.WriteRow(shortened)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// pagerWriter feeds output into a running pager process.
+type pagerWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func (p *pagerWriter) Write(b []byte) (int, error) {
+	n, err := p.stdin.Write(b)
+	// The user quitting the pager before reading everything is not an error.
+	if errors.Is(err, syscall.EPIPE) {
+		return len(b), nil
+	}
+	return n, err
+}
+
+func (p *pagerWriter) Close() error {
+	p.stdin.Close()
+	err := p.cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("pager exited with status %d", exitErr.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("pager failed: %w", err)
+	}
+	return nil
+}
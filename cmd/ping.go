@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"splunk_cli/splunk"
+)
+
+// pingCmd checks connectivity and authentication against the Splunk server.
+// It returns an error (and thus a non-zero exit status) if either fails.
+func pingCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("ping", "ping")
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	username, err := client.Ping()
+	if err != nil {
+		return fmt.Errorf("ping to %s failed: %w", baseCfg.Host, err)
+	}
+	fmt.Printf("OK\nHost: %s\nAuth: %s\nUser: %s\n", baseCfg.Host, authMethod(&baseCfg), username)
+	return nil
+}
+
+// authMethod describes which credentials the client will use.
+func authMethod(cfg *splunk.Config) string {
+	if cfg.Token != "" {
+		return "token"
+	}
+	return "basic"
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+const detachedJobsFile = "detached.jsonl"
+
+// detachedJob is a record of a job the user detached from, kept so the SID
+// isn't lost.
+type detachedJob struct {
+	SID        string `json:"sid"`
+	Host       string `json:"host"`
+	App        string `json:"app,omitempty"`
+	SPL        string `json:"spl"`
+	Earliest   string `json:"earliest,omitempty"`
+	Latest     string `json:"latest,omitempty"`
+	DetachedAt string `json:"detachedAt"`
+}
+
+// recordDetachedJob appends a detached job to the recovery file in configDir
+// and returns the file's path.
+func recordDetachedJob(configDir string, job detachedJob) (string, error) {
+	if configDir == "" {
+		return "", errors.New("no config directory available")
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("could not create config directory: %w", err)
+	}
+	path := filepath.Join(configDir, detachedJobsFile)
+	line, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("could not open recovery file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return "", fmt.Errorf("could not write recovery file: %w", err)
+	}
+	return path, nil
+}
+
+// loadDetachedJobs reads all recorded detached jobs, oldest first.
+func loadDetachedJobs(configDir string) ([]detachedJob, error) {
+	f, err := os.Open(filepath.Join(configDir, detachedJobsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open recovery file: %w", err)
+	}
+	defer f.Close()
+
+	var jobs []detachedJob
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var job detachedJob
+		if err := json.Unmarshal([]byte(line), &job); err != nil {
+			continue // Skip corrupt lines rather than losing every record.
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, scanner.Err()
+}
+
+// detachRecorder returns a callback for waitForJobInteractive that records the
+// detached job and tells the user where the record was saved.
+func detachRecorder(cfg *splunk.Config, spl string, opts splunk.SearchOptions) func(sid string) {
+	return func(sid string) {
+		path, err := recordDetachedJob(cfg.ConfigDir, detachedJob{
+			SID:        sid,
+			Host:       cfg.Host,
+			App:        cfg.App,
+			SPL:        spl,
+			Earliest:   opts.Earliest,
+			Latest:     opts.Latest,
+			DetachedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not record detached job: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Job details saved to %s. Use 'splunk-cli recover' to list detached jobs.\n", path)
+	}
+}
+
+// recoverFlags holds the flags of the recover command.
+type recoverFlags struct {
+	count *int
+}
+
+// addRecoverFlags defines the flags of the recover command.
+func addRecoverFlags(fs *flag.FlagSet) *recoverFlags {
+	return &recoverFlags{
+		count: fs.Int("count", 20, "Number of most recent detached jobs to list (0 for all)"),
+	}
+}
+
+// recoverCmd lists the most recently detached jobs.
+func recoverCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("recover", "recover")
+	flags := addRecoverFlags(fs)
+	fs.Parse(args)
+
+	jobs, err := loadDetachedJobs(baseCfg.ConfigDir)
+	if err != nil {
+		return err
+	}
+	if *flags.count > 0 && len(jobs) > *flags.count {
+		jobs = jobs[len(jobs)-*flags.count:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DETACHED AT\tSID\tHOST\tEARLIEST\tLATEST\tSPL")
+	for i := len(jobs) - 1; i >= 0; i-- {
+		j := jobs[i]
+		spl := strings.Join(strings.Fields(j.SPL), " ")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", j.DetachedAt, j.SID, j.Host, j.Earliest, j.Latest, spl)
+	}
+	return w.Flush()
+}
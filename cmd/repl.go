@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"splunk_cli/output"
+	"splunk_cli/splunk"
+)
+
+// replSession is the state of an interactive session that persists between
+// searches.
+type replSession struct {
+	client   *splunk.Client
+	earliest string
+	latest   string
+	limit    int
+	format   string
+	fields   []string
+
+	allowDestructive bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // cancels the running search, nil at the prompt
+}
+
+// replFlags holds the flags of the repl command.
+type replFlags struct {
+	earliest         *string
+	latest           *string
+	format           *string
+	allowDestructive *bool
+}
+
+// addReplFlags defines the flags of the repl command.
+func addReplFlags(fs *flag.FlagSet) *replFlags {
+	return &replFlags{
+		earliest:         fs.String("earliest", "-15m", "Initial earliest time of the session (change with ':earliest')"),
+		latest:           fs.String("latest", "now", "Initial latest time of the session (change with ':latest')"),
+		format:           fs.String("format", "table", "Initial output format: 'json', 'ndjson', 'csv', 'table', or 'raw' (change with ':format')"),
+		allowDestructive: fs.Bool("allow-destructive", false, "Allow searches that modify indexed data ('| delete', '| collect')"),
+	}
+}
+
+// replCmd reads SPL line by line, runs each search with one authenticated
+// client, and prints its results. Lines starting with ':' change the session
+// state instead; Ctrl-C cancels the running search without leaving the REPL.
+func replCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("repl", "repl")
+	flags := addReplFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if !isFlagSet(fs, "limit") && baseCfg.Limit == 0 {
+		baseCfg.Limit = 100 // an interactive session should not dump entire result sets
+	}
+	if err := validateReplFormat(*flags.format); err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+	client, err := splunk.NewClient(&baseCfg, true)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	s := &replSession{
+		client:   client,
+		earliest: *flags.earliest,
+		latest:   *flags.latest,
+		limit:    baseCfg.Limit,
+		format:   *flags.format,
+
+		allowDestructive: *flags.allowDestructive,
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT)
+	defer signal.Stop(sigChan)
+	go func() {
+		for range sigChan {
+			s.mu.Lock()
+			if s.cancel != nil {
+				s.cancel()
+			} else {
+				fmt.Fprint(os.Stderr, "\n(type :quit or press Ctrl-D to exit)\nsplunk> ")
+			}
+			s.mu.Unlock()
+		}
+	}()
+
+	fmt.Fprintln(os.Stderr, "Connected to", baseCfg.Host+". Type SPL to search, :help for commands, :quit to exit.")
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var pending strings.Builder
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(os.Stderr, "splunk> ")
+		} else {
+			fmt.Fprint(os.Stderr, "   ...> ")
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		// A trailing backslash continues the search on the next line.
+		if strings.HasSuffix(line, `\`) {
+			pending.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			continue
+		}
+		pending.WriteString(line)
+		input := strings.TrimSpace(pending.String())
+		pending.Reset()
+
+		switch {
+		case input == "":
+		case strings.HasPrefix(input, ":"):
+			quit, err := s.command(input)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			if quit {
+				return nil
+			}
+		default:
+			if err := s.search(input, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		}
+	}
+}
+
+// command applies a ':' command to the session. It reports true for ':quit'.
+func (s *replSession) command(input string) (bool, error) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(input, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "quit", "q", "exit":
+		return true, nil
+	case "help", "h":
+		fmt.Fprint(os.Stderr, `Commands:
+  :earliest <time>    Set the earliest time (e.g. -1h, @d)
+  :latest <time>      Set the latest time (e.g. now)
+  :limit <n>          Set the maximum number of results shown (0 for all)
+  :format <format>    Set the output format: json, ndjson, csv, table, or raw
+  :fields [a,b,...]   Show only these fields (no argument shows all fields)
+  :show               Show the session settings
+  :quit               Exit (also Ctrl-D)
+End a line with '\' to continue the search on the next line.
+`)
+	case "earliest":
+		s.earliest = arg
+	case "latest":
+		s.latest = arg
+	case "limit":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return false, fmt.Errorf("invalid limit '%s': must be a number >= 0", arg)
+		}
+		s.limit = n
+	case "format":
+		if err := validateReplFormat(arg); err != nil {
+			return false, err
+		}
+		s.format = arg
+	case "fields":
+		var fields listFlag
+		fields.Set(arg)
+		s.fields = fields
+	case "show":
+		fields := "(all)"
+		if len(s.fields) > 0 {
+			fields = strings.Join(s.fields, ",")
+		}
+		fmt.Fprintf(os.Stderr, "earliest=%s latest=%s limit=%d format=%s fields=%s\n", s.earliest, s.latest, s.limit, s.format, fields)
+	default:
+		return false, fmt.Errorf("unknown command ':%s' (type :help for a list)", name)
+	}
+	return false, nil
+}
+
+// search runs spl over the session's time range and writes its results to w.
+func (s *replSession) search(spl string, w io.Writer) error {
+	if err := checkDestructive(spl, s.allowDestructive); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.cancel = nil
+		s.mu.Unlock()
+	}()
+
+	sid, _, err := s.client.StartSearch(spl, splunk.SearchOptions{Earliest: s.earliest, Latest: s.latest})
+	if err != nil {
+		return err
+	}
+	status, err := s.client.WaitForJobStatus(ctx, sid)
+	if errors.Is(err, context.Canceled) {
+		cancelCtx, cancelCancel := context.WithTimeout(context.Background(), batchCancelTimeout)
+		defer cancelCancel()
+		if err := s.client.CancelSearchContext(cancelCtx, sid); err != nil {
+			return fmt.Errorf("search interrupted, but job %s could not be cancelled: %w", sid, err)
+		}
+		return errors.New("search cancelled")
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.client.Results(ctx, sid, splunk.ResultsOptions{Limit: s.limit})
+	if err != nil {
+		return err
+	}
+	if len(s.fields) > 0 {
+		if rows, err = applyTransforms(rows, []rowTransform{keepFields(s.fields)}); err != nil {
+			return err
+		}
+	}
+	policy := columnPolicy{firstField: "_time"}
+	if s.format == "table" {
+		policy.scanRows = tableUnionScanRows
+	}
+	if err := writeRows(newReplWriter(s.format, w), rows, policy); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "(%d of %d results, %d events scanned, sid %s)\n", len(rows), status.ResultCount, status.EventCount, sid)
+	return nil
+}
+
+// validateReplFormat checks an output format of the REPL.
+func validateReplFormat(format string) error {
+	switch format {
+	case "json", "ndjson", "csv", "table", "raw":
+		return nil
+	}
+	return fmt.Errorf("invalid format '%s': must be 'json', 'ndjson', 'csv', 'table', or 'raw'", format)
+}
+
+// newReplWriter returns the ResultWriter for a REPL output format. JSON is
+// always pretty-printed, since the REPL is read by a person.
+func newReplWriter(format string, w io.Writer) output.ResultWriter {
+	switch format {
+	case "ndjson":
+		return output.NewNDJSONWriter(w)
+	case "csv":
+		return output.NewCSVWriter(w)
+	case "raw":
+		return output.NewRawWriter(w)
+	case "json":
+		return output.NewJSONWriter(w, false)
+	default:
+		return output.NewTableWriter(w)
+	}
+}
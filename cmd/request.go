@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"splunk_cli/splunk"
+)
+
+// requestFlags holds the flags of the request command.
+type requestFlags struct {
+	method     *string
+	path       *string
+	outputMode *string
+	params     keyValueFlag
+}
+
+// addRequestFlags defines the flags of the request command.
+func addRequestFlags(fs *flag.FlagSet) *requestFlags {
+	flags := &requestFlags{
+		method:     fs.String("method", "GET", "HTTP method: GET, POST, or DELETE"),
+		path:       fs.String("path", "", "Endpoint path, e.g. 'saved/searches' (namespaced by --app) or 'services/server/info' (used as-is)"),
+		outputMode: fs.String("output-mode", "json", "Value of the output_mode parameter (empty to omit)"),
+	}
+	fs.Var(&flags.params, "param", "Request parameter as key=value; can be repeated")
+	return flags
+}
+
+// requestCmd sends a raw request to any REST endpoint and prints the response
+// body, for management endpoints the CLI does not wrap.
+func requestCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("request", "request")
+	flags := addRequestFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	switch strings.ToUpper(*flags.method) {
+	case "GET", "POST", "DELETE":
+	default:
+		return fmt.Errorf("invalid --method '%s': must be GET, POST, or DELETE", *flags.method)
+	}
+	var segments []string
+	for _, s := range strings.Split(*flags.path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return errors.New("--path is required")
+	}
+	values := url.Values{}
+	for _, kv := range flags.params {
+		values.Add(kv.key, kv.value)
+	}
+	if *flags.outputMode != "" && !values.Has("output_mode") {
+		values.Set("output_mode", *flags.outputMode)
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	body, err := client.DoAPI(context.Background(), *flags.method, segments, values)
+	if err != nil {
+		return err
+	}
+	os.Stdout.Write(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		fmt.Println()
+	}
+	return nil
+}
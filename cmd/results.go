@@ -4,19 +4,59 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"time"
 
 	"splunk_cli/splunk"
 )
 
+// resultsFlags holds the flags of the results command.
+type resultsFlags struct {
+	jobFlags       *sidFlags
+	offset         *int
+	wait           *bool
+	timeout        *time.Duration
+	resultsTimeout *time.Duration
+	silent         *bool
+	onSignal       *string
+	out            *outputFlags
+}
+
+// addResultsFlags defines the flags of the results command.
+func addResultsFlags(fs *flag.FlagSet) *resultsFlags {
+	return &resultsFlags{
+		jobFlags:       addSIDFlags(fs),
+		offset:         fs.Int("offset", 0, "Index of the first result to return (use with --limit to fetch a window; offset+limit must not exceed the result count)"),
+		wait:           fs.Bool("wait", false, "Wait for the job to finish instead of failing when it is still running"),
+		timeout:        fs.Duration("timeout", 10*time.Minute, "With --wait, maximum time to wait for the job (0 for no limit)"),
+		resultsTimeout: fs.Duration("results-timeout", 0, "Timeout for fetching the results (0 for no limit)"),
+		silent:         fs.Bool("silent", false, "Suppress progress messages"),
+		onSignal:       addOnSignalFlag(fs),
+		out:            addOutputFlags(fs),
+	}
+}
+
 func resultsCmd(args []string, baseCfg splunk.Config) error {
-	fs := flag.NewFlagSet("results", flag.ExitOnError)
-	sid := fs.String("sid", "", "Search ID (SID) of the job")
-	silent := fs.Bool("silent", false, "Suppress progress messages")
+	fs := newCommandFlagSet("results", "results")
+	flags := addResultsFlags(fs)
 	addCommonFlags(fs, &baseCfg)
 	fs.Parse(args)
 
-	if *sid == "" {
-		return errors.New("--sid is a required argument for 'results'")
+	if err := flags.out.validate(baseCfg.Debug); err != nil {
+		return err
+	}
+	baseCfg.DebugMaxFieldBytes = *flags.out.maxFieldBytes
+	if err := validateOnSignal(*flags.onSignal); err != nil {
+		return err
+	}
+	if !*flags.wait && (isFlagSet(fs, "timeout") || isFlagSet(fs, "on-signal")) {
+		return errors.New("--timeout and --on-signal require --wait")
+	}
+	sid, err := flags.jobFlags.resolve("results")
+	if err != nil {
+		return err
+	}
+	if *flags.offset < 0 {
+		return errors.New("--offset must be >= 0")
 	}
 	if baseCfg.Host == "" {
 		return errors.New("--host is required")
@@ -25,7 +65,7 @@ func resultsCmd(args []string, baseCfg splunk.Config) error {
 		return err
 	}
 
-	client, err := splunk.NewClient(&baseCfg, *silent)
+	client, err := splunk.NewClient(&baseCfg, *flags.silent)
 	if err != nil {
 		return err
 	}
@@ -33,22 +73,27 @@ func resultsCmd(args []string, baseCfg splunk.Config) error {
 		printDebugConfig(&baseCfg, client.Log)
 	}
 
-	done, jobState, _, _, err := client.JobStatus(*sid)
-	if err != nil {
-		return err
-	}
-	if !done {
-		return fmt.Errorf("job %s is not complete yet (state: %s)", *sid, jobState)
-	}
-	if jobState == "FAILED" {
-		return fmt.Errorf("cannot get results, job %s failed", *sid)
+	var status *splunk.JobStatus
+	if *flags.wait {
+		// Re-attaching behaves like 'run': bounded by --timeout, and Ctrl-C
+		// offers to cancel the job or detach from it again.
+		status, err = waitForJobInteractive(client, sid, *flags.timeout, *flags.onSignal, nil)
+		if err != nil || status == nil {
+			return err
+		}
+	} else {
+		done, jobState, messages, resultCount, err := client.JobStatus(sid)
+		if err != nil {
+			return err
+		}
+		if !done {
+			return fmt.Errorf("job %s is not complete yet (state: %s); use --wait to wait for it", sid, jobState)
+		}
+		if jobState == "FAILED" {
+			return fmt.Errorf("cannot get results, job %s failed", sid)
+		}
+		status = &splunk.JobStatus{IsDone: done, DispatchState: jobState, Messages: messages, ResultCount: resultCount}
 	}
 
-	client.Log.Println("Fetching results...")
-	results, err := client.Results(*sid, baseCfg.Limit)
-	if err != nil {
-		return err
-	}
-	fmt.Println(results)
-	return nil
+	return printJobResults(client, sid, status, *flags.offset, baseCfg.Limit, *flags.resultsTimeout, flags.out)
 }
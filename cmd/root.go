@@ -5,44 +5,33 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"syscall"
 	"time"
 
 	"splunk_cli/splunk"
 )
 
-func Execute() {
-	var configPath string
-
+// Execute runs the CLI. version is the build version set by the linker and is
+// used for the default User-Agent.
+func Execute(version string) {
 	// NOTE: We are not using flag.Parse() here at the top level anymore.
 	// Each command will be responsible for parsing its own flags.
-	// We manually check for the config flag.
-	for i, arg := range os.Args {
-		if (arg == "--config" || arg == "-config") && i+1 < len(os.Args) {
-			configPath = os.Args[i+1]
-			// Remove the flag and its value from os.Args so subcommands don't see it.
-			os.Args = append(os.Args[:i], os.Args[i+2:]...)
-			break
-		}
-	}
-
+	// We manually extract the global flags so subcommands don't see them.
+	configPath := extractGlobalFlag("config")
+	configDirFlag := extractGlobalFlag("config-dir")
+	configJSON := extractGlobalFlag("config-json")
 
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	log := &splunk.Logger{}
-	baseCfg, cfgPath, err := splunk.LoadConfigFromFile(configPath)
+	baseCfg, err := loadBaseConfig(version, configPath, configDirFlag, configJSON)
 	if err != nil {
-		log.Printf("Warning: could not load config file at %s: %v", cfgPath, err)
-	}
-
-	if baseCfg.HTTPTimeout == 0 {
-		baseCfg.HTTPTimeout = 30 * time.Second
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCodeError)
 	}
 
-	splunk.ProcessEnvVars(&baseCfg)
-
 	var cmdErr error
 	switch os.Args[1] {
 	case "run":
@@ -53,6 +42,36 @@ func Execute() {
 		cmdErr = statusCmd(os.Args[2:], baseCfg)
 	case "results":
 		cmdErr = resultsCmd(os.Args[2:], baseCfg)
+	case "batch":
+		cmdErr = batchCmd(os.Args[2:], baseCfg)
+	case "jobs":
+		cmdErr = jobsCmd(os.Args[2:], baseCfg)
+	case "recover":
+		cmdErr = recoverCmd(os.Args[2:], baseCfg)
+	case "inspect":
+		cmdErr = inspectCmd(os.Args[2:], baseCfg)
+	case "timeline":
+		cmdErr = timelineCmd(os.Args[2:], baseCfg)
+	case "export":
+		cmdErr = exportCmd(os.Args[2:], baseCfg)
+	case "cancel":
+		cmdErr = cancelCmd(os.Args[2:], baseCfg)
+	case "wait":
+		cmdErr = waitCmd(os.Args[2:], baseCfg)
+	case "ping":
+		cmdErr = pingCmd(os.Args[2:], baseCfg)
+	case "whoami":
+		cmdErr = whoamiCmd(os.Args[2:], baseCfg)
+	case "metrics":
+		cmdErr = metricsCmd(os.Args[2:], baseCfg)
+	case "request":
+		cmdErr = requestCmd(os.Args[2:], baseCfg)
+	case "repl":
+		cmdErr = replCmd(os.Args[2:], baseCfg)
+	case "saved":
+		cmdErr = savedCmd(os.Args[2:], baseCfg)
+	case "macros":
+		cmdErr = macrosCmd(os.Args[2:], baseCfg)
 	case "help":
 		printHelp(os.Args[2:])
 	case "--help", "-h":
@@ -67,7 +86,97 @@ func Execute() {
 	}
 
 	if cmdErr != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v", cmdErr)
-		os.Exit(1)
+		code := exitCodeFor(cmdErr)
+		if code != exitCodeBrokenPipe {
+			// The downstream reader going away is not worth an error message.
+			fmt.Fprintf(os.Stderr, "Error: %v", cmdErr)
+		}
+		os.Exit(code)
+	}
+}
+
+// exitCodeFor returns the process exit status for the error of a command.
+func exitCodeFor(err error) int {
+	var exitErr *exitError
+	var notFound *splunk.JobNotFoundError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, syscall.EPIPE):
+		return exitCodeBrokenPipe
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.As(err, &notFound):
+		return exitCodeJobNotFound
+	default:
+		return exitCodeError
+	}
+}
+
+// defaultHTTPTimeout is the per-request timeout when none is configured.
+const defaultHTTPTimeout = 30 * time.Second
+
+// loadBaseConfig builds the configuration that every subcommand starts from.
+// Its sources are applied here, from lowest to highest precedence:
+//
+//  1. built-in defaults (HTTP timeout, User-Agent)
+//  2. the config file (--config, or config.json in the config directory)
+//  3. --config-json
+//  4. environment variables (SPLUNK_HOST, SPLUNK_TOKEN, ...)
+//
+// Command-line flags take precedence over all of them: addCommonFlags uses the
+// resolved values as flag defaults, so only flags that are given override them.
+//
+// Problems with the default config file are only warnings, so the CLI still
+// works from the environment; an explicit --config or --config-json that
+// cannot be used is an error.
+func loadBaseConfig(version, configPath, configDirFlag, configJSON string) (splunk.Config, error) {
+	log := &splunk.Logger{}
+	configDir, err := splunk.ResolveConfigDir(configDirFlag)
+	if err != nil {
+		log.Printf("Warning: could not determine config directory: %v\n", err)
+	}
+	cfg, cfgPath, err := splunk.LoadConfigFromFile(configPath, configDir)
+	if err != nil && configPath != "" {
+		return cfg, fmt.Errorf("could not load config file at %s: %w", cfgPath, err)
+	} else if err != nil {
+		log.Printf("Warning: could not load config file at %s: %v", cfgPath, err)
+	}
+	if configJSON != "" {
+		if err := splunk.MergeConfigJSON(&cfg, configJSON); err != nil {
+			return cfg, fmt.Errorf("--config-json: %w", err)
+		}
+	}
+
+	// Defaults fill in what neither the file nor --config-json set, so they
+	// rank below both, and environment variables still override them.
+	if cfg.HTTPTimeout == 0 {
+		cfg.HTTPTimeout = defaultHTTPTimeout
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = splunk.DefaultUserAgent(version)
+	}
+	cfg.ConfigDir = configDir
+
+	splunk.ProcessEnvVars(&cfg)
+	return cfg, nil
+}
+
+// extractGlobalFlag returns the value of a global flag given as "--name value",
+// "-name value", or "--name=value", removing it from os.Args.
+func extractGlobalFlag(name string) string {
+	for i, arg := range os.Args {
+		if (arg == "--"+name || arg == "-"+name) && i+1 < len(os.Args) {
+			value := os.Args[i+1]
+			os.Args = append(os.Args[:i], os.Args[i+2:]...)
+			return value
+		}
+		for _, prefix := range []string{"--" + name + "=", "-" + name + "="} {
+			if strings.HasPrefix(arg, prefix) {
+				os.Args = append(os.Args[:i], os.Args[i+1:]...)
+				return strings.TrimPrefix(arg, prefix)
+			}
+		}
 	}
-}
\ No newline at end of file
+	return ""
+}
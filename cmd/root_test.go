@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+// configEnvVars are the environment variables loadBaseConfig reads, cleared
+// for every precedence case.
+var configEnvVars = []string{"SPLUNK_HOST", "SPLUNK_TOKEN", "SPLUNK_USER", "SPLUNK_PASSWORD", "SPLUNK_APP", "SPLUNK_EARLIEST", "SPLUNK_LATEST"}
+
+// TestLoadBaseConfigPrecedence sets each field in every source up to a given
+// one and checks that the highest source wins:
+// defaults < config file < --config-json < environment < flags.
+func TestLoadBaseConfigPrecedence(t *testing.T) {
+	const version = "test"
+	layers := []string{"default", "file", "config-json", "env", "flag"}
+	fields := []struct {
+		key  string // config file and --config-json key
+		env  string // environment variable, if any
+		flag string // common flag, if any
+		get  func(splunk.Config) string
+		// values per layer, in the form the layer takes; "" if the field has
+		// no such source. file and config-json values are JSON.
+		values [5]string
+	}{
+		{"host", "SPLUNK_HOST", "host", func(c splunk.Config) string { return c.Host },
+			[5]string{"", `"https://file:8089"`, `"https://json:8089"`, "https://env:8089", "https://flag:8089"}},
+		{"token", "SPLUNK_TOKEN", "token", func(c splunk.Config) string { return c.Token },
+			[5]string{"", `"file-token"`, `"json-token"`, "env-token", "flag-token"}},
+		{"user", "SPLUNK_USER", "user", func(c splunk.Config) string { return c.User },
+			[5]string{"", `"file-user"`, `"json-user"`, "env-user", "flag-user"}},
+		{"password", "SPLUNK_PASSWORD", "password", func(c splunk.Config) string { return c.Password },
+			[5]string{"", `"file-pw"`, `"json-pw"`, "env-pw", "flag-pw"}},
+		{"app", "SPLUNK_APP", "app", func(c splunk.Config) string { return c.App },
+			[5]string{"", `"file_app"`, `"json_app"`, "env_app", "flag_app"}},
+		{"defaultEarliest", "SPLUNK_EARLIEST", "", func(c splunk.Config) string { return c.DefaultEarliest },
+			[5]string{"", `"-1d"`, `"-2d"`, "-3d", ""}},
+		{"defaultLatest", "SPLUNK_LATEST", "", func(c splunk.Config) string { return c.DefaultLatest },
+			[5]string{"", `"-1m"`, `"-2m"`, "-3m", ""}},
+		{"httpTimeout", "", "http-timeout", func(c splunk.Config) string { return c.HTTPTimeout.String() },
+			[5]string{defaultHTTPTimeout.String(), `"11s"`, `"12s"`, "", "14s"}},
+		{"userAgent", "", "user-agent", func(c splunk.Config) string { return c.UserAgent },
+			[5]string{splunk.DefaultUserAgent(version), `"file-agent"`, `"json-agent"`, "", "flag-agent"}},
+		{"retries", "", "retries", func(c splunk.Config) string { return strconv.Itoa(c.Retries) },
+			[5]string{"0", `1`, `2`, "", "4"}},
+	}
+	// want normalizes a layer's value to what the getter returns.
+	want := func(layer int, v string) string {
+		if layer == 1 || layer == 2 {
+			return strings.Trim(v, `"`)
+		}
+		return v
+	}
+
+	for _, f := range fields {
+		for top := range layers {
+			if f.values[top] == "" && top > 0 {
+				continue // the field has no such source
+			}
+			t.Run(f.key+"/"+layers[top], func(t *testing.T) {
+				for _, name := range configEnvVars {
+					t.Setenv(name, "")
+				}
+				dir := t.TempDir()
+				fileJSON := "{}"
+				if top >= 1 && f.values[1] != "" {
+					fileJSON = `{"` + f.key + `":` + f.values[1] + `}`
+				}
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(fileJSON), 0600); err != nil {
+					t.Fatal(err)
+				}
+				configJSON := ""
+				if top >= 2 && f.values[2] != "" {
+					configJSON = `{"` + f.key + `":` + f.values[2] + `}`
+				}
+				if top >= 3 && f.env != "" && f.values[3] != "" {
+					t.Setenv(f.env, f.values[3])
+				}
+
+				cfg, err := loadBaseConfig(version, "", dir, configJSON)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fs := flag.NewFlagSet("test", flag.ContinueOnError)
+				addCommonFlags(fs, &cfg)
+				var args []string
+				if top == 4 {
+					args = []string{"--" + f.flag, f.values[4]}
+				}
+				if err := fs.Parse(args); err != nil {
+					t.Fatal(err)
+				}
+
+				expected := ""
+				for layer := top; layer >= 0; layer-- {
+					if f.values[layer] != "" {
+						expected = want(layer, f.values[layer])
+						break
+					}
+				}
+				if got := f.get(cfg); got != expected {
+					t.Errorf("%s set up to %s: got %q, want %q", f.key, layers[top], got, expected)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"splunk_cli/output"
+)
+
+// Column policies for --format table.
+const (
+	tableColumnsUnion = "union"
+	tableColumnsFirst = "first"
+)
+
+// tableUnionScanRows caps how many rows are scanned for columns under the
+// "union" policy, so column discovery stays bounded on huge result sets.
+const tableUnionScanRows = 1000
+
+// columnPolicy selects the rows whose keys make up the header fields.
+type columnPolicy struct {
+	firstRowOnly bool     // Only the first row's keys
+	scanRows     int      // Union of the keys of this many rows; 0 for all
+	firstField   string   // Moved to the front of the fields when present
+	columns      []string // Fixed header fields in this order; no rows are scanned
+}
+
+// writeRows decodes JSON result rows and writes them through rw. The header
+// fields are the keys of the rows selected by policy, in the order Splunk
+// returned them, first-seen first.
+func writeRows(rw output.ResultWriter, rows []json.RawMessage, policy columnPolicy) error {
+	sink := newRowSink(rw, policy)
+	for _, raw := range rows {
+		if err := sink.add(raw); err != nil {
+			return err
+		}
+	}
+	return sink.close()
+}
+
+// rowSink writes JSON result rows through a ResultWriter as they arrive. Rows
+// are only held back until the header fields can be chosen: none with fixed
+// columns, one with firstRowOnly, scanRows with scanRows set, and otherwise all
+// of them, since a CSV or Parquet header must cover every row. With
+// growHeader, for writers whose fields only order the keys of later rows, rows
+// are never held back; the header is written again whenever a row brings new
+// keys.
+type rowSink struct {
+	rw         output.ResultWriter
+	policy     columnPolicy
+	growHeader bool
+
+	fields  []string
+	seen    map[string]bool
+	pending []json.RawMessage
+	started bool // the header has been written
+}
+
+func newRowSink(rw output.ResultWriter, policy columnPolicy) *rowSink {
+	return &rowSink{rw: rw, policy: policy, seen: map[string]bool{}}
+}
+
+// add writes raw, or holds it back until the header is known.
+func (s *rowSink) add(raw json.RawMessage) error {
+	if s.started {
+		if s.growHeader {
+			if err := s.scan(raw); err != nil {
+				return err
+			}
+		}
+		return s.writeRow(raw)
+	}
+	if s.policy.columns == nil {
+		if err := s.scan(raw); err != nil {
+			return err
+		}
+	}
+	s.pending = append(s.pending, raw)
+
+	scanned := len(s.pending)
+	switch {
+	case s.policy.columns != nil, s.growHeader:
+	case s.policy.firstRowOnly && scanned >= 1:
+	case s.policy.scanRows > 0 && scanned >= s.policy.scanRows:
+	default:
+		return nil
+	}
+	return s.start()
+}
+
+// close writes any rows still held back and closes the writer.
+func (s *rowSink) close() error {
+	if !s.started {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	return s.rw.Close()
+}
+
+// scan adds the keys of raw to the header fields. When growHeader is set and
+// the header has already been written, new keys write it again.
+func (s *rowSink) scan(raw json.RawMessage) error {
+	keys, err := orderedKeys(raw)
+	if err != nil {
+		return err
+	}
+	grew := false
+	for _, k := range keys {
+		if !s.seen[k] {
+			s.seen[k] = true
+			s.fields = append(s.fields, k)
+			grew = true
+		}
+	}
+	if grew && s.started {
+		return s.rw.WriteHeader(s.header())
+	}
+	return nil
+}
+
+// start writes the header and the rows held back so far.
+func (s *rowSink) start() error {
+	s.started = true
+	if err := s.rw.WriteHeader(s.header()); err != nil {
+		return err
+	}
+	for _, raw := range s.pending {
+		if err := s.writeRow(raw); err != nil {
+			return err
+		}
+	}
+	s.pending = nil
+	return nil
+}
+
+// header returns the header fields for the keys seen so far.
+func (s *rowSink) header() []string {
+	if s.policy.columns != nil {
+		return s.policy.columns
+	}
+	fields := slices.Clone(s.fields)
+	if i := slices.Index(fields, s.policy.firstField); i > 0 {
+		fields = slices.Insert(slices.Delete(fields, i, i+1), 0, s.policy.firstField)
+	}
+	return fields
+}
+
+func (s *rowSink) writeRow(raw json.RawMessage) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		return fmt.Errorf("failed to decode result row: %w", err)
+	}
+	return s.rw.WriteRow(row)
+}
+
+// orderedKeys returns the top-level keys of a JSON object in document order.
+func orderedKeys(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("failed to decode result row: expected a JSON object")
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result row: %w", err)
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, fmt.Errorf("failed to decode result row: %w", err)
+		}
+	}
+	return keys, nil
+}
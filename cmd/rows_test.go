@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"splunk_cli/output"
+)
+
+// recordingWriter records the rows it is given and the header in effect for
+// each of them.
+type recordingWriter struct {
+	header  []string
+	headers [][]string // the header at the time of each row
+}
+
+func (r *recordingWriter) WriteHeader(fields []string) error {
+	r.header = fields
+	return nil
+}
+
+func (r *recordingWriter) WriteRow(map[string]any) error {
+	r.headers = append(r.headers, r.header)
+	return nil
+}
+
+func (r *recordingWriter) Close() error { return nil }
+
+func rawRows(rows ...string) []json.RawMessage {
+	raw := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		raw[i] = json.RawMessage(row)
+	}
+	return raw
+}
+
+// TestRowSinkHoldsRowsUntilHeader checks how many rows each column policy
+// keeps back before the first one is written.
+func TestRowSinkHoldsRowsUntilHeader(t *testing.T) {
+	rows := rawRows(`{"a":1}`, `{"b":2}`, `{"c":3}`, `{"d":4}`)
+	tests := []struct {
+		name       string
+		policy     columnPolicy
+		growHeader bool
+		held       int // rows added before the first is written
+		header     []string
+	}{
+		{name: "fixed columns", policy: columnPolicy{columns: []string{"x"}}, held: 0, header: []string{"x"}},
+		{name: "first row", policy: columnPolicy{firstRowOnly: true}, held: 0, header: []string{"a"}},
+		{name: "scan rows", policy: columnPolicy{scanRows: 3}, held: 2, header: []string{"a", "b", "c"}},
+		{name: "all rows", policy: columnPolicy{}, held: 4, header: []string{"a", "b", "c", "d"}},
+		{name: "growing header", policy: columnPolicy{}, growHeader: true, held: 0, header: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := &recordingWriter{}
+			sink := newRowSink(rw, tt.policy)
+			sink.growHeader = tt.growHeader
+			held := 0
+			for _, raw := range rows {
+				if err := sink.add(raw); err != nil {
+					t.Fatal(err)
+				}
+				if len(rw.headers) == 0 {
+					held++
+				}
+			}
+			if err := sink.close(); err != nil {
+				t.Fatal(err)
+			}
+			if held != tt.held {
+				t.Errorf("%d rows held back, want %d", held, tt.held)
+			}
+			if len(rw.headers) != len(rows) {
+				t.Fatalf("%d rows written, want %d", len(rw.headers), len(rows))
+			}
+			if !slices.Equal(rw.headers[0], tt.header) {
+				t.Errorf("header of the first row = %v, want %v", rw.headers[0], tt.header)
+			}
+		})
+	}
+}
+
+// TestRowSinkGrowingHeaderKeyOrder checks that JSON rows written while the
+// header grows come out exactly as with a header chosen from all rows.
+func TestRowSinkGrowingHeaderKeyOrder(t *testing.T) {
+	rows := rawRows(
+		`{"host":"a","count":1}`,
+		`{"zone":"eu","host":"b","_time":"t2"}`,
+		`{"count":3,"_time":"t3","extra":true}`,
+	)
+	write := func(growHeader bool) string {
+		var buf bytes.Buffer
+		sink := newRowSink(output.NewNDJSONWriter(&buf), columnPolicy{firstField: "_time"})
+		sink.growHeader = growHeader
+		for _, raw := range rows {
+			if err := sink.add(raw); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := sink.close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	streamed, buffered := write(true), write(false)
+	if streamed != buffered {
+		t.Errorf("streamed output differs from buffered output:\n%s\nwant:\n%s", streamed, buffered)
+	}
+	if want := `{"_time":"t2","host":"b","zone":"eu"}`; !strings.Contains(streamed, want) {
+		t.Errorf("output lacks %s:\n%s", want, streamed)
+	}
+}
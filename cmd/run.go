@@ -2,34 +2,110 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"splunk_cli/splunk"
 )
 
+// runFlags holds the flags of the run command.
+type runFlags struct {
+	search            *searchFlags
+	timeout           *time.Duration
+	resultsTimeout    *time.Duration
+	retryOnFailed     *int
+	requireDoneWithin *time.Duration
+	silent            *bool
+	onSignal          *string
+	hosts             listFlag
+	hostConcurrency   *int
+	metaFile          *string
+	messagesFile      *string
+	counts            *countFlags
+	incremental       *incrementalFlags
+	out               *outputFlags
+}
+
+// addRunFlags defines the flags of the run command.
+func addRunFlags(fs *flag.FlagSet) *runFlags {
+	flags := &runFlags{
+		search:            addSearchFlags(fs),
+		timeout:           fs.Duration("timeout", 10*time.Minute, "Timeout for the search job to complete"),
+		resultsTimeout:    fs.Duration("results-timeout", 10*time.Minute, "Timeout for fetching the results once the job is done (0 for no limit)"),
+		retryOnFailed:     fs.Int("retry-on-failed", 0, "Dispatch the search again up to this many times when the job fails on the server (not for syntax errors)"),
+		requireDoneWithin: fs.Duration("require-done-within", 0, "Finalize the job if it is not done within this time and print its partial results (exit code 5)"),
+		silent:            fs.Bool("silent", false, "Suppress progress messages"),
+		onSignal:          addOnSignalFlag(fs),
+		hostConcurrency:   fs.Int("host-concurrency", 4, "With --hosts, the maximum number of hosts searched at once"),
+		metaFile:          fs.String("meta-file", "", "Write a JSON metadata file (SPL, time range, SID, counts, timing) describing the run"),
+		messagesFile:      addMessagesFileFlag(fs),
+		counts:            addCountFlags(fs),
+		incremental:       addIncrementalFlags(fs),
+		out:               addOutputFlags(fs),
+	}
+	fs.Var(&flags.hosts, "hosts", "Run the search on each of these comma-separated hosts and merge the results, labeled with _host; can be repeated")
+	return flags
+}
+
+// runCmd dispatches a search, waits for it, and prints the results.
+// The result payload is the only thing ever written to stdout; every progress,
+// warning, and prompt message goes to stderr so the two streams can be
+// redirected independently.
 func runCmd(args []string, baseCfg splunk.Config) error {
-	fs := flag.NewFlagSet("run", flag.ExitOnError)
-	spl := fs.String("spl", "", "SPL query to execute")
-	file := fs.String("file", "", "Read SPL query from a file (use '-' for stdin)")
-	fs.StringVar(file, "f", "", "Shorthand for --file")
-	earliest := fs.String("earliest", "", "Search earliest time (e.g., -1h, @d, 1672531200)")
-	latest := fs.String("latest", "", "Search latest time (e.g., now, @d, 1672617600)")
-	timeout := fs.Duration("timeout", 10*time.Minute, "Total timeout for the run command")
-	silent := fs.Bool("silent", false, "Suppress progress messages")
+	fs := newCommandFlagSet("run", "run")
+	flags := addRunFlags(fs)
 	addCommonFlags(fs, &baseCfg)
 	fs.Parse(args)
 
-	finalSpl, err := getSplQuery(*spl, *file)
+	if err := flags.out.validate(baseCfg.Debug); err != nil {
+		return err
+	}
+	baseCfg.DebugMaxFieldBytes = *flags.out.maxFieldBytes
+	if err := validateOnSignal(*flags.onSignal); err != nil {
+		return err
+	}
+	if err := flags.counts.validate(); err != nil {
+		return err
+	}
+	if err := flags.incremental.validate(); err != nil {
+		return err
+	}
+	if *flags.retryOnFailed < 0 {
+		return errors.New("--retry-on-failed must be >= 0")
+	}
+	if *flags.requireDoneWithin < 0 {
+		return errors.New("--require-done-within must be >= 0")
+	}
+	if *flags.requireDoneWithin > 0 && *flags.timeout > 0 && *flags.requireDoneWithin >= *flags.timeout {
+		return errors.New("--require-done-within must be shorter than --timeout")
+	}
+	finalSpl, err := flags.search.query()
+	if err != nil {
+		return err
+	}
+	searchOpts, err := flags.search.options(&baseCfg)
 	if err != nil {
 		return err
 	}
+	if len(flags.hosts) > 0 {
+		if *flags.hostConcurrency < 1 {
+			return errors.New("--host-concurrency must be >= 1")
+		}
+		for _, name := range []string{"host", "meta-file", "sid-file", "print-url", "show-window", "like-sid", "preflight", "require-done-within", "messages-file", "assert-count-min", "assert-count-max", "count-only", "retry-on-failed", "incremental", "state-key", "raw-passthrough"} {
+			if isFlagSet(fs, name) {
+				return fmt.Errorf("--%s cannot be used with --hosts", name)
+			}
+		}
+		if err := promptForCredentials(&baseCfg); err != nil {
+			return err
+		}
+		return runFanOut(flags.hosts, *flags.hostConcurrency, finalSpl, searchOpts, baseCfg, *flags.silent, *flags.timeout, *flags.resultsTimeout, *flags.onSignal, flags.out)
+	}
 	if baseCfg.Host == "" {
 		return errors.New("--host is required")
 	}
@@ -37,68 +113,193 @@ func runCmd(args []string, baseCfg splunk.Config) error {
 		return err
 	}
 
-	client, err := splunk.NewClient(&baseCfg, *silent)
+	client, err := splunk.NewClient(&baseCfg, *flags.silent)
 	if err != nil {
 		return err
 	}
 	if baseCfg.Debug {
 		printDebugConfig(&baseCfg, client.Log)
 	}
+	if err := flags.search.applyLikeSID(client, &searchOpts); err != nil {
+		return err
+	}
+	if err := flags.incremental.apply(&baseCfg, client.Log, &searchOpts); err != nil {
+		return err
+	}
+	flags.search.preflightCheck(client, finalSpl)
 
+	// A spinner shows that the tool is busy while Splunk dispatches and runs
+	// the job. Log messages are written through it so they do not mix with it.
+	showSpinner := !*flags.silent && !baseCfg.Debug
 	client.Log.Println("Connecting to Splunk and starting search job...")
-	sid, err := client.StartSearch(finalSpl, *earliest, *latest)
-	if err != nil {
-		return err
+	startedAt := time.Now()
+	waitTimeout := *flags.timeout
+	if *flags.requireDoneWithin > 0 {
+		waitTimeout = *flags.requireDoneWithin
 	}
-	client.Log.Printf("Job started with SID: %s\n", sid)
+	onDetach := detachRecorder(&baseCfg, finalSpl, searchOpts)
 
-	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
-	defer cancel()
-	sigChan := make(chan os.Signal, 2)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// dispatch starts the search and waits for it. partial is true when the
+	// job was finalized for --require-done-within.
+	dispatch := func() (sid string, status *splunk.JobStatus, partial bool, err error) {
+		spin := startSpinner("Dispatching search...", showSpinner)
+		client.Log.Out = spin
+		sid, reused, err := client.StartSearch(finalSpl, searchOpts)
+		spin.Stop()
+		if err != nil {
+			return "", nil, false, err
+		}
+		if reused {
+			client.Log.Printf("Reusing existing job with SID: %s\n", sid)
+		} else {
+			client.Log.Printf("Job started with SID: %s\n", sid)
+		}
+		flags.search.printJobURL(&baseCfg, sid)
+		flags.search.printSearchWindow(client, sid)
+		if err := writeSIDFile(*flags.search.sidFile, sid); err != nil {
+			return "", nil, false, err
+		}
 
-	errChan := make(chan error, 1)
-	go func() {
-		errChan <- client.WaitForJob(ctx, sid)
-	}()
+		spin = startSpinner("Running search...", showSpinner)
+		client.Log.Out = spin
+		status, err = waitForJobInteractive(client, sid, waitTimeout, *flags.onSignal, onDetach)
+		spin.Stop()
+		var timeoutErr *waitTimeoutError
+		if *flags.requireDoneWithin > 0 && errors.As(err, &timeoutErr) {
+			partial = true
+			status, err = finalizeJob(client, sid, *flags.requireDoneWithin, *flags.timeout, *flags.onSignal, onDetach)
+		}
+		if status != nil || err != nil {
+			// The messages of a failed job are recorded too; they say why it failed.
+			if msgErr := recordJobMessages(sid, finalJobMessages(status, err), *flags.messagesFile); msgErr != nil && err == nil {
+				err = msgErr
+			}
+		}
+		return sid, status, partial, err
+	}
 
-	select {
-	case err := <-errChan:
-		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+	sid, status, partial, err := dispatch()
+	for attempt := 1; attempt <= *flags.retryOnFailed; attempt++ {
+		var failed *splunk.JobFailedError
+		if !errors.As(err, &failed) {
+			break
+		}
+		if isSearchSyntaxError(failed.Messages) {
+			client.Log.Println("Not retrying: the search failed with a syntax error.")
+			break
+		}
+		client.Log.Printf("%v\nRetrying in %v (attempt %d of %d)...\n", err, failedJobRetryDelay, attempt, *flags.retryOnFailed)
+		time.Sleep(failedJobRetryDelay)
+		// A retry must run the search again, not pick up the job that failed.
+		searchOpts.ReuseMaxAge = 0
+		sid, status, partial, err = dispatch()
+	}
+	if err != nil || status == nil {
+		return err
+	}
+	finishedAt := time.Now()
+	client.Log.Printf("Search complete: %d results from %d events in %s.\n", status.ResultCount, status.EventCount, finishedAt.Sub(startedAt).Round(time.Millisecond))
+
+	resultsErr := flags.counts.printResults(client, sid, status, baseCfg.Limit, *flags.resultsTimeout, flags.out)
+	if *flags.metaFile != "" {
+		meta := runMetadata{
+			SPL:         finalSpl,
+			Earliest:    searchOpts.Earliest,
+			Latest:      searchOpts.Latest,
+			SID:         sid,
+			ResultCount: status.ResultCount,
+			StartedAt:   startedAt.UTC().Format(time.RFC3339),
+			FinishedAt:  finishedAt.UTC().Format(time.RFC3339),
+			Host:        baseCfg.Host,
+			App:         baseCfg.App,
+		}
+		if err := writeMetaFile(*flags.metaFile, meta); err != nil {
 			return err
 		}
-		if errors.Is(err, context.DeadlineExceeded) {
-			return fmt.Errorf("command timed out after %v", *timeout)
+	}
+	if resultsErr != nil {
+		return resultsErr
+	}
+	// Partial results stop short of the job's latest time, so the next run
+	// must start from the old checkpoint again.
+	if !partial {
+		if err := flags.incremental.save(client, &baseCfg, sid); err != nil {
+			return err
 		}
-	case <-sigChan:
-		signal.Stop(sigChan)
-		fmt.Fprintf(os.Stderr, "\n^C detected. What would you like to do?\n  (c)ancel the job on Splunk\n  (d)etach and let it run in the background\nChoice [c/d]: ")
-
-		choiceChan := make(chan string)
-		go func() {
-			choiceChan <- getChoiceFromTTY()
-		}()
-
-		secondSigChan := make(chan os.Signal, 1)
-		signal.Notify(secondSigChan, syscall.SIGINT, syscall.SIGTERM)
-		defer signal.Stop(secondSigChan)
-
-		select {
-		case choice := <-choiceChan:
-			if strings.ToLower(choice) == "d" {
-				fmt.Fprintf(os.Stderr, "Detaching from job %s. Use 'results' command to fetch results later.", sid)
-				return nil
+	}
+	if err := flags.counts.check(status.ResultCount); err != nil {
+		return err
+	}
+	if partial {
+		return withExitCode(exitCodePartial, "job %s was not done within %v; the results are partial", sid, *flags.requireDoneWithin)
+	}
+	return nil
+}
+
+// failedJobRetryDelay is how long 'run --retry-on-failed' waits before
+// dispatching a failed search again.
+const failedJobRetryDelay = 5 * time.Second
+
+// syntaxErrorMarkers are substrings of the messages of a job that failed
+// because of its SPL rather than a transient problem, which a retry cannot fix.
+var syntaxErrorMarkers = []string{
+	"unknown search command",
+	"error in '",
+	"syntax error",
+	"unbalanced quotes",
+	"unbalanced parentheses",
+	"missing a closing",
+}
+
+// isSearchSyntaxError reports whether the messages of a failed job say that
+// the search itself is invalid.
+func isSearchSyntaxError(messages []splunk.SplunkMessage) bool {
+	for _, msg := range messages {
+		text := strings.ToLower(msg.Text)
+		for _, marker := range syntaxErrorMarkers {
+			if strings.Contains(text, marker) {
+				return true
 			}
-		case <-secondSigChan:
 		}
-		return client.CancelSearch(sid)
 	}
+	return false
+}
+
+// finalizeJob finalizes a job that was not done within deadline, so that it
+// stops and keeps the results found so far, and waits for it to become done
+// within what is left of timeout (0 for no limit).
+func finalizeJob(client *splunk.Client, sid string, deadline, timeout time.Duration, onSignal string, onDetach func(sid string)) (*splunk.JobStatus, error) {
+	fmt.Fprintf(os.Stderr, "Warning: job %s was not done within %v; finalizing it and fetching partial results.\n", sid, deadline)
+	if err := client.FinalizeSearch(context.Background(), sid); err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		timeout -= deadline
+	}
+	return waitForJobInteractive(client, sid, timeout, onSignal, onDetach)
+}
 
-	client.Log.Println("Fetching results...")
-	results, err := client.Results(sid, baseCfg.Limit)
+// runMetadata is the document written by 'run --meta-file'.
+type runMetadata struct {
+	SPL         string `json:"spl"`
+	Earliest    string `json:"earliest"`
+	Latest      string `json:"latest"`
+	SID         string `json:"sid"`
+	ResultCount int    `json:"resultCount"`
+	StartedAt   string `json:"startedAt"`
+	FinishedAt  string `json:"finishedAt"`
+	Host        string `json:"host"`
+	App         string `json:"app"`
+}
+
+// writeMetaFile writes the run metadata as indented JSON.
+func writeMetaFile(path string, meta runMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal run metadata: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write metadata file '%s': %w", path, err)
 	}
-	fmt.Println(results)
 	return nil
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+// TestRunStdoutHoldsOnlyResults checks that progress and warnings go to stderr
+// and the result document is the only thing written to stdout.
+func TestRunStdoutHoldsOnlyResults(t *testing.T) {
+	rows := []map[string]any{
+		{"host": "web01", "count": "12"},
+		{"host": "web02", "count": "3"},
+	}
+	fake := newFakeSplunk(t, []string{"host", "count"}, rows)
+	fake.messages = []map[string]string{{"type": "WARN", "text": "Search was truncated"}}
+
+	var runErr error
+	stdout, stderr := captureOutput(t, func() {
+		runErr = runCmd([]string{"--host", fake.URL, "--token", "zzzzzzzz", "--spl", "index=main | stats count by host", "--format", "json"}, splunk.Config{})
+	})
+	if runErr != nil {
+		t.Fatalf("run failed: %v\nstderr:\n%s", runErr, stderr)
+	}
+
+	var doc struct {
+		Results []map[string]any `json:"results"`
+	}
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("stdout is not a JSON document: %v\nstdout:\n%s", err, stdout)
+	}
+	if dec.More() {
+		t.Errorf("stdout has more than the result document:\n%s", stdout)
+	}
+	if !reflect.DeepEqual(doc.Results, rows) {
+		t.Errorf("results = %v, want %v", doc.Results, rows)
+	}
+	if !strings.Contains(stderr, "Job started with SID: fake.1") {
+		t.Errorf("progress messages missing from stderr:\n%s", stderr)
+	}
+	if strings.Contains(stdout, "fake.1") {
+		t.Errorf("SID leaked to stdout:\n%s", stdout)
+	}
+}
+
+// TestRunFanOutPartialResults checks that --hosts merges the rows of a host
+// whose peers failed but fails unless --allow-partial is given.
+func TestRunFanOutPartialResults(t *testing.T) {
+	healthy := newFakeSplunk(t, []string{"n"}, []map[string]any{{"n": "1"}})
+	degraded := newFakeSplunk(t, []string{"n"}, []map[string]any{{"n": "2"}})
+	degraded.messages = []map[string]string{{"type": "WARN", "text": "Search results might be incomplete: the search process on peer idx1 ended prematurely."}}
+	hosts := healthy.URL + "," + degraded.URL
+
+	for _, allowPartial := range []bool{false, true} {
+		args := []string{"--hosts", hosts, "--token", "zzzzzzzz", "--spl", "index=main", "--silent", "--format", "ndjson"}
+		if allowPartial {
+			args = append(args, "--allow-partial")
+		}
+		var runErr error
+		stdout, stderr := captureOutput(t, func() { runErr = runCmd(args, splunk.Config{}) })
+
+		if allowPartial && runErr != nil {
+			t.Errorf("--allow-partial: unexpected error %v", runErr)
+		}
+		if !allowPartial && (runErr == nil || !strings.Contains(runErr.Error(), "incomplete on 1 of 2 hosts")) {
+			t.Errorf("err = %v, want results incomplete on 1 of 2 hosts", runErr)
+		}
+		if lines := strings.Count(stdout, "\n"); lines != 2 {
+			t.Errorf("got %d rows, want the rows of both hosts:\n%s", lines, stdout)
+		}
+		if !strings.Contains(stderr, "Warning: "+degraded.URL+": WARN: Search results might be incomplete") {
+			t.Errorf("partial warning missing from stderr:\n%s", stderr)
+		}
+	}
+}
+
+// TestRunMessagesFileUsesFinalStatus checks that --messages-file records the
+// messages of the status the run acted on, without fetching the job again.
+func TestRunMessagesFileUsesFinalStatus(t *testing.T) {
+	fake := newFakeSplunk(t, []string{"n"}, []map[string]any{{"n": "1"}})
+	fake.messages = []map[string]string{{"type": "INFO", "text": "Your timerange was substituted"}}
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+
+	var err error
+	captureOutput(t, func() {
+		err = runCmd([]string{"--host", fake.URL, "--token", "zzzzzzzz", "--spl", "index=main", "--silent", "--messages-file", path}, splunk.Config{})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record jobMessageRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("messages file is not one JSON record: %v\n%s", err, data)
+	}
+	if record.SID != "fake.1" || record.Type != "INFO" || record.Text != "Your timerange was substituted" {
+		t.Errorf("record = %+v", record)
+	}
+
+	statusFetches := 0
+	for _, req := range fake.requests {
+		if req == "GET /services/search/jobs/fake.1" {
+			statusFetches++
+		}
+	}
+	if statusFetches != 1 {
+		t.Errorf("job status fetched %d times, want once: %v", statusFetches, fake.requests)
+	}
+}
+
+// TestFinalJobMessages checks where the recorded messages come from.
+func TestFinalJobMessages(t *testing.T) {
+	info := []splunk.SplunkMessage{{Type: "INFO", Text: "done"}}
+	fatal := []splunk.SplunkMessage{{Type: "FATAL", Text: "bad"}}
+	failed := &splunk.JobFailedError{SID: "fake.1", Messages: fatal}
+
+	if got := finalJobMessages(&splunk.JobStatus{Messages: info}, failed); !reflect.DeepEqual(got, info) {
+		t.Errorf("with a status: %v, want its messages", got)
+	}
+	if got := finalJobMessages(nil, fmt.Errorf("wrapped: %w", failed)); !reflect.DeepEqual(got, fatal) {
+		t.Errorf("with only a failure: %v, want its messages", got)
+	}
+	if got := finalJobMessages(nil, errors.New("timed out")); got != nil {
+		t.Errorf("with another error: %v, want none", got)
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"splunk_cli/splunk"
+)
+
+// savedFlags holds the flags of 'saved create'.
+type savedFlags struct {
+	name       *string
+	spl        *string
+	file       *string
+	splLines   lineFlag
+	cron       *string
+	earliest   *string
+	latest     *string
+	update     *bool
+	appContext *string
+}
+
+// addSavedFlags defines the flags of 'saved create'.
+func addSavedFlags(fs *flag.FlagSet) *savedFlags {
+	flags := &savedFlags{
+		name:       fs.String("name", "", "Name of the saved search"),
+		spl:        fs.String("spl", "", "SPL query to save (cannot be used with --file)"),
+		file:       fs.String("file", "", "Read SPL query from a file (use '-' for stdin)"),
+		cron:       fs.String("cron", "", "Cron schedule (e.g. '*/15 * * * *'); the search is unscheduled if omitted"),
+		earliest:   fs.String("earliest", "", "Dispatch earliest time (e.g., -24h@h)"),
+		latest:     fs.String("latest", "", "Dispatch latest time (e.g., now)"),
+		update:     fs.Bool("update", false, "Overwrite the saved search if it already exists"),
+		appContext: fs.String("app-context", "", "With --update, 'auto' updates the saved search in the app and owner where it already exists, found by querying all namespaces, instead of the --app namespace"),
+	}
+	fs.StringVar(flags.file, "f", "", "Shorthand for --file")
+	fs.Var(&flags.splLines, "spl-line", splLineFlagUsage)
+	return flags
+}
+
+// savedCmd manages saved searches.
+func savedCmd(args []string, baseCfg splunk.Config) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errors.New("usage: splunk-cli saved create --name <name> (--spl <query> | --spl-line <line>... | --file <path>) [options]")
+	}
+
+	fs := newCommandFlagSet("saved create", "saved")
+	flags := addSavedFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args[1:])
+
+	if *flags.name == "" {
+		return errors.New("--name is a required argument for 'saved create'")
+	}
+	if *flags.appContext != "" && *flags.appContext != "auto" {
+		return fmt.Errorf("invalid --app-context '%s': must be 'auto'", *flags.appContext)
+	}
+	if *flags.appContext == "auto" && !*flags.update {
+		return errors.New("--app-context auto requires --update, since only an existing saved search has a namespace")
+	}
+	finalSpl, err := getSplQuery(*flags.spl, *flags.file, flags.splLines)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+	if *flags.appContext == "auto" {
+		owner, app, err := client.ResolveSavedSearchNamespace(*flags.name)
+		if err != nil {
+			return err
+		}
+		// The client shares baseCfg, so its requests now use this namespace.
+		baseCfg.Owner, baseCfg.App = owner, app
+		client.Log.Printf("Saved search '%s' lives in %s/%s.\n", *flags.name, owner, app)
+	}
+
+	objectURL, err := client.CreateSavedSearch(*flags.name, finalSpl, *flags.cron, splunk.SavedSearchOptions{
+		Earliest: *flags.earliest,
+		Latest:   *flags.latest,
+		Update:   *flags.update,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(objectURL)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+// TestSavedCreateAppContextAuto checks that --app-context auto updates a saved
+// search in the namespace it is found in, and fails when the name is unknown
+// or exists in several apps.
+func TestSavedCreateAppContextAuto(t *testing.T) {
+	type acl struct {
+		App     string `json:"app"`
+		Owner   string `json:"owner"`
+		Sharing string `json:"sharing"`
+	}
+	type entry struct {
+		Name string `json:"name"`
+		ACL  acl    `json:"acl"`
+	}
+	tests := []struct {
+		name     string
+		entries  []entry
+		app      string
+		wantPost string // path of the update request
+		wantErr  string
+	}{
+		{
+			name:     "private search",
+			entries:  []entry{{"Errors", acl{"ops", "alice", "user"}}},
+			wantPost: "/servicesNS/alice/ops/saved/searches/Errors",
+		},
+		{
+			name:     "shared search",
+			entries:  []entry{{"Errors", acl{"ops", "alice", "app"}}, {"Errors by host", acl{"search", "bob", "app"}}},
+			wantPost: "/servicesNS/nobody/ops/saved/searches/Errors",
+		},
+		{
+			name:    "not found",
+			entries: []entry{{"Errors by host", acl{"search", "bob", "app"}}},
+			wantErr: "not found in any namespace",
+		},
+		{
+			name:    "ambiguous",
+			entries: []entry{{"Errors", acl{"ops", "alice", "app"}}, {"Errors", acl{"search", "bob", "global"}}},
+			wantErr: "exists in multiple namespaces (nobody/ops, nobody/search)",
+		},
+		{
+			name:     "ambiguous resolved by --app",
+			entries:  []entry{{"Errors", acl{"ops", "alice", "app"}}, {"Errors", acl{"search", "bob", "global"}}},
+			app:      "search",
+			wantPost: "/servicesNS/nobody/search/saved/searches/Errors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var posts []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == "GET" && r.URL.Path == "/servicesNS/-/-/saved/searches":
+					json.NewEncoder(w).Encode(map[string]any{"entry": tt.entries})
+				case r.Method == "POST":
+					mu.Lock()
+					posts = append(posts, r.URL.Path)
+					mu.Unlock()
+					json.NewEncoder(w).Encode(map[string]any{})
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			args := []string{"create", "--host", srv.URL, "--token", "zzzzzzzz", "--name", "Errors", "--spl", "index=main error", "--update", "--app-context", "auto"}
+			if tt.app != "" {
+				args = append(args, "--app", tt.app)
+			}
+			var err error
+			stdout, _ := captureOutput(t, func() { err = savedCmd(args, splunk.Config{}) })
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if len(posts) != 0 {
+					t.Errorf("saved search written despite the error: %v", posts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(posts) != 1 || posts[0] != tt.wantPost {
+				t.Errorf("updates = %v, want one to %s", posts, tt.wantPost)
+			}
+			if !strings.HasSuffix(strings.TrimSpace(stdout), tt.wantPost) {
+				t.Errorf("printed URL %q, want it to end in %s", stdout, tt.wantPost)
+			}
+		})
+	}
+}
+
+func TestSavedCreateAppContextRequiresUpdate(t *testing.T) {
+	err := savedCmd([]string{"create", "--name", "Errors", "--spl", "index=main", "--app-context", "auto"}, splunk.Config{})
+	if err == nil || !strings.Contains(err.Error(), "requires --update") {
+		t.Errorf("err = %v, want --update to be required", err)
+	}
+}
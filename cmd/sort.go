@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// transformingCommands are the SPL commands that turn events into a table of
+// statistics. A --sort after one of them orders the table, not the events.
+var transformingCommands = map[string]bool{
+	"chart":       true,
+	"contingency": true,
+	"geostats":    true,
+	"rare":        true,
+	"stats":       true,
+	"timechart":   true,
+	"top":         true,
+	"tstats":      true,
+	"xyseries":    true,
+}
+
+// sortClause builds the '| sort' command for --sort specs of the form
+// field[:asc|desc]. 'sort 0' is used so Splunk does not truncate the results
+// to its default of 10,000 rows.
+func sortClause(specs []string) (string, error) {
+	var b strings.Builder
+	b.WriteString("| sort 0")
+	for _, spec := range specs {
+		field, order, _ := strings.Cut(spec, ":")
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return "", fmt.Errorf("invalid --sort '%s': expected field[:asc|desc]", spec)
+		}
+		switch strings.ToLower(strings.TrimSpace(order)) {
+		case "", "asc":
+			b.WriteString(" +")
+		case "desc":
+			b.WriteString(" -")
+		default:
+			return "", fmt.Errorf("invalid --sort '%s': order must be 'asc' or 'desc'", spec)
+		}
+		if strings.ContainsAny(field, " \t\",=") {
+			field = `"` + strings.ReplaceAll(field, `"`, `\"`) + `"`
+		}
+		b.WriteString(field)
+	}
+	return b.String(), nil
+}
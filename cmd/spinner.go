@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"splunk_cli/splunk"
+
+	"golang.org/x/term"
+)
+
+// spinnerFrames are drawn in turn, one per spinnerInterval.
+const (
+	spinnerFrames   = `|/-\`
+	spinnerInterval = 100 * time.Millisecond
+)
+
+// spinner animates a progress indicator on the last line of stderr while a
+// command waits for Splunk. It is also an io.Writer for the client's log:
+// a message written through it clears the spinner line first, and the
+// spinner is redrawn below it on the next tick.
+type spinner struct {
+	mu     sync.Mutex
+	label  string
+	frame  int
+	shown  bool // the spinner is on the current line
+	done   chan struct{}
+	once   sync.Once
+	active bool
+}
+
+// startSpinner starts a spinner with label. It only animates when enabled and
+// stderr is a terminal; otherwise writes pass straight through.
+func startSpinner(label string, enabled bool) *spinner {
+	s := &spinner{label: label, done: make(chan struct{})}
+	if !enabled || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return s
+	}
+	s.active = true
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.mu.Lock()
+				if s.active {
+					fmt.Fprintf(os.Stderr, "\r%c %s", spinnerFrames[s.frame%len(spinnerFrames)], s.label)
+					s.frame++
+					s.shown = true
+				}
+				s.mu.Unlock()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func (s *spinner) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clear()
+	return os.Stderr.Write(b)
+}
+
+// Stop stops the animation and clears the spinner line. It is safe to call
+// more than once.
+func (s *spinner) Stop() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		s.active = false
+		s.clear()
+		s.mu.Unlock()
+	})
+}
+
+// clear erases the spinner line; s.mu must be held.
+func (s *spinner) clear() {
+	if s.shown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		s.shown = false
+	}
+}
+
+// stopSpinner stops the spinner drawing on the client's log, if there is one,
+// so that a prompt written straight to stderr is not overdrawn.
+func stopSpinner(client *splunk.Client) {
+	if s, ok := client.Log.Out.(*spinner); ok {
+		s.Stop()
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"splunk_cli/splunk"
+)
+
+// destructiveCommands are the SPL commands that modify indexed data and are
+// refused unless --allow-destructive is given.
+var destructiveCommands = map[string]bool{
+	"collect": true,
+	"delete":  true,
+}
+
+// pipelineCommands returns the lowercased names of the commands of an SPL
+// pipeline after the initial search, including those in subsearches. Pipes
+// inside quoted strings are not treated as command separators.
+func pipelineCommands(spl string) []string {
+	var commands []string
+	inQuote := false
+	for i := 0; i < len(spl); i++ {
+		switch c := spl[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '|' || c == '[':
+			start := i + 1
+			for start < len(spl) && isSpace(spl[start]) {
+				start++
+			}
+			end := start
+			for end < len(spl) && !isSpace(spl[end]) && !strings.ContainsRune("|[]", rune(spl[end])) {
+				end++
+			}
+			if end > start {
+				commands = append(commands, strings.ToLower(spl[start:end]))
+			}
+		}
+	}
+	return commands
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// lastCommand returns the name of the last command of an SPL pipeline, or ""
+// for a plain search. Pipes inside quoted strings and subsearch brackets are
+// not treated as command separators.
+func lastCommand(spl string) string {
+	last := -1
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(spl); i++ {
+		switch c := spl[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth > 0 {
+				depth--
+			}
+		case c == '|' && depth == 0:
+			last = i
+		}
+	}
+	if last < 0 {
+		return ""
+	}
+	fields := strings.Fields(spl[last+1:])
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// checkDestructive returns an error if spl uses a command that modifies
+// indexed data, unless allow is set.
+func checkDestructive(spl string, allow bool) error {
+	if allow {
+		return nil
+	}
+	for _, cmd := range pipelineCommands(spl) {
+		if destructiveCommands[cmd] {
+			return fmt.Errorf("the search uses the destructive command '%s'; pass --allow-destructive to run it", cmd)
+		}
+	}
+	return nil
+}
+
+// preflightCheck warns on stderr when the authenticated user is unlikely to be
+// allowed to run spl: without the 'search' capability, or running '| delete'
+// without 'delete_by_keyword'. It only warns, since the capabilities Splunk
+// reports do not cover every restriction (e.g. index access).
+func preflightCheck(client *splunk.Client, spl string) {
+	ctx, err := client.CurrentContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check capabilities before dispatch: %v\n", err)
+		return
+	}
+	if len(ctx.Capabilities) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: Splunk did not report the capabilities of '%s' (roles: %s); skipping the pre-flight check\n", ctx.Username, strings.Join(ctx.Roles, ", "))
+		return
+	}
+	if !ctx.HasCapability("search") {
+		fmt.Fprintf(os.Stderr, "Warning: user '%s' (roles: %s) lacks the 'search' capability; the search will likely be rejected\n", ctx.Username, strings.Join(ctx.Roles, ", "))
+	}
+	if slices.Contains(pipelineCommands(spl), "delete") && !ctx.HasCapability("delete_by_keyword") {
+		fmt.Fprintf(os.Stderr, "Warning: user '%s' lacks the 'delete_by_keyword' capability required by '| delete'\n", ctx.Username)
+	}
+}
@@ -1,25 +1,51 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"splunk_cli/splunk"
 )
 
+// startFlags holds the flags of the start command.
+type startFlags struct {
+	search    *searchFlags
+	silent    *bool
+	format    *string
+	emitFetch *bool
+}
+
+// addStartFlags defines the flags of the start command.
+func addStartFlags(fs *flag.FlagSet) *startFlags {
+	return &startFlags{
+		search:    addSearchFlags(fs),
+		silent:    fs.Bool("silent", true, "Suppress progress messages"),
+		format:    fs.String("format", "sid", "Output format: 'sid' (bare SID) or 'json'"),
+		emitFetch: fs.Bool("emit-fetch-command", false, emitFetchCommandUsage),
+	}
+}
+
 func startCmd(args []string, baseCfg splunk.Config) error {
-	fs := flag.NewFlagSet("start", flag.ExitOnError)
-	spl := fs.String("spl", "", "SPL query to execute")
-	file := fs.String("file", "", "Read SPL query from a file (use '-' for stdin)")
-	fs.StringVar(file, "f", "", "Shorthand for --file")
-	earliest := fs.String("earliest", "", "Search earliest time (e.g., -1h, @d, 1672531200)")
-	latest := fs.String("latest", "", "Search latest time (e.g., now, @d, 1672617600)")
-	silent := fs.Bool("silent", true, "Suppress progress messages")
+	fs := newCommandFlagSet("start", "start")
+	flags := addStartFlags(fs)
 	addCommonFlags(fs, &baseCfg)
 	fs.Parse(args)
 
-	finalSpl, err := getSplQuery(*spl, *file)
+	if *flags.format != "sid" && *flags.format != "json" {
+		return fmt.Errorf("invalid --format '%s': must be 'sid' or 'json'", *flags.format)
+	}
+
+	finalSpl, err := flags.search.query()
+	if err != nil {
+		return err
+	}
+	searchOpts, err := flags.search.options(&baseCfg)
 	if err != nil {
 		return err
 	}
@@ -30,19 +56,133 @@ func startCmd(args []string, baseCfg splunk.Config) error {
 		return err
 	}
 
-	client, err := splunk.NewClient(&baseCfg, *silent)
+	client, err := splunk.NewClient(&baseCfg, *flags.silent)
 	if err != nil {
 		return err
 	}
 	if baseCfg.Debug {
 		printDebugConfig(&baseCfg, client.Log)
 	}
+	if err := flags.search.applyLikeSID(client, &searchOpts); err != nil {
+		return err
+	}
+	flags.search.preflightCheck(client, finalSpl)
 
 	client.Log.Println("Connecting to Splunk and starting search job...")
-	sid, err := client.StartSearch(finalSpl, *earliest, *latest)
+	submittedAt := time.Now().UTC()
+	sid, reused, err := client.StartSearch(finalSpl, searchOpts)
 	if err != nil {
 		return err
 	}
-	fmt.Println(sid)
+	if reused {
+		client.Log.Printf("Reusing existing job with SID: %s\n", sid)
+	}
+	flags.search.printJobURL(&baseCfg, sid)
+	flags.search.printSearchWindow(client, sid)
+	if err := writeSIDFile(*flags.search.sidFile, sid); err != nil {
+		return err
+	}
+	var fetch *fetchInfo
+	if *flags.emitFetch {
+		if fetch, err = newFetchInfo(client, &baseCfg, sid); err != nil {
+			return err
+		}
+	}
+	if *flags.format == "sid" {
+		if fetch != nil {
+			fmt.Println(fetch.Command)
+		} else {
+			fmt.Println(sid)
+		}
+		return nil
+	}
+
+	// The job exists at this point, so a failed status lookup must not cost
+	// the caller its SID. A job that is not visible yet is still queued.
+	_, jobState, _, _, err := client.JobStatus(sid)
+	if errors.Is(err, splunk.ErrJobStatusNotFound) {
+		jobState = "QUEUED"
+		fmt.Fprintf(os.Stderr, "Warning: job %s is not visible yet; reporting it as QUEUED\n", sid)
+	} else if err != nil {
+		jobState = ""
+		fmt.Fprintf(os.Stderr, "Warning: could not get the dispatch state of job %s: %v\n", sid, err)
+	}
+	out, err := json.MarshalIndent(startOutput{
+		SID:           sid,
+		Host:          baseCfg.Host,
+		App:           baseCfg.App,
+		DispatchState: jobState,
+		SubmittedAt:   submittedAt.Format(time.RFC3339),
+		Reused:        reused,
+		Fetch:         fetch,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal start output: %w", err)
+	}
+	fmt.Println(string(out))
 	return nil
 }
+
+// startOutput is the document printed by 'start --format json'.
+type startOutput struct {
+	SID           string     `json:"sid"`
+	Host          string     `json:"host"`
+	App           string     `json:"app"`
+	DispatchState string     `json:"dispatchState"`
+	SubmittedAt   string     `json:"submittedAt"`
+	Reused        bool       `json:"reused"`
+	Fetch         *fetchInfo `json:"fetch,omitempty"`
+}
+
+// emitFetchCommandUsage is the help text of start's --emit-fetch-command flag.
+const emitFetchCommandUsage = "Print a ready-to-run 'results' command for the job instead of the bare SID (with --format json, add a 'fetch' object with the command and the REST results URL)"
+
+// fetchInfo tells another program how to fetch the results of a job started
+// with 'start --emit-fetch-command'.
+type fetchInfo struct {
+	Command    string `json:"command"`
+	ResultsURL string `json:"resultsUrl"`
+}
+
+// newFetchInfo builds the fetch instructions for job sid. They never contain
+// secrets: the token, the password, and any credentials embedded in the host
+// URL are left out, so the caller must supply them (e.g. via SPLUNK_TOKEN) as
+// for any other command.
+func newFetchInfo(client *splunk.Client, cfg *splunk.Config, sid string) (*fetchInfo, error) {
+	resultsURL, err := client.ResultsURL(sid)
+	if err != nil {
+		return nil, err
+	}
+	host, err := url.Parse(cfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host URL '%s'", cfg.Host)
+	}
+	host.User = nil
+
+	args := []string{"splunk-cli", "results", "--host", host.String()}
+	if cfg.App != "" {
+		args = append(args, "--app", cfg.App)
+	}
+	if cfg.Insecure {
+		args = append(args, "--insecure")
+	}
+	if cfg.SkipHostnameVerify {
+		args = append(args, "--skip-hostname-verify")
+	}
+	args = append(args, "--sid", sid)
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return &fetchInfo{Command: strings.Join(quoted, " "), ResultsURL: resultsURL}, nil
+}
+
+// shellQuote quotes s for a POSIX shell, leaving it bare when it has no
+// characters the shell would interpret.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+// TestStartJSONStatusUnavailable checks that start --format json still prints
+// the SID of the job it created when the job's status cannot be read.
+func TestStartJSONStatusUnavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      func(w http.ResponseWriter)
+		wantState   string
+		wantWarning string
+	}{
+		{
+			name:      "running",
+			status:    func(w http.ResponseWriter) { w.Write([]byte(`{"entry":[{"content":{"dispatchState":"RUNNING"}}]}`)) },
+			wantState: "RUNNING",
+		},
+		{
+			name:        "not visible yet",
+			status:      func(w http.ResponseWriter) { w.Write([]byte(`{"entry":[]}`)) },
+			wantState:   "QUEUED",
+			wantWarning: "Warning: job fake.1 is not visible yet",
+		},
+		{
+			name:        "status request fails",
+			status:      func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			wantState:   "",
+			wantWarning: "Warning: could not get the dispatch state of job fake.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "POST" {
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"sid":"fake.1"}`))
+					return
+				}
+				tt.status(w)
+			}))
+			defer srv.Close()
+
+			var err error
+			stdout, stderr := captureOutput(t, func() {
+				err = startCmd([]string{"--host", srv.URL, "--token", "zzzzzzzz", "--spl", "index=main", "--format", "json"}, splunk.Config{})
+			})
+			if err != nil {
+				t.Fatalf("start failed after creating the job: %v", err)
+			}
+			var out startOutput
+			if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+				t.Fatalf("stdout is not the start document: %v\n%s", err, stdout)
+			}
+			if out.SID != "fake.1" || out.DispatchState != tt.wantState {
+				t.Errorf("sid = %q, dispatchState = %q; want fake.1, %q", out.SID, out.DispatchState, tt.wantState)
+			}
+			if tt.wantWarning == "" && stderr != "" || !strings.Contains(stderr, tt.wantWarning) {
+				t.Errorf("stderr = %q, want warning %q", stderr, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -1,21 +1,42 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"splunk_cli/splunk"
 )
 
+// statusFlags holds the flags of the status command.
+type statusFlags struct {
+	jobFlags *sidFlags
+	wait     *bool
+	timeout  *time.Duration
+}
+
+// addStatusFlags defines the flags of the status command.
+func addStatusFlags(fs *flag.FlagSet) *statusFlags {
+	return &statusFlags{
+		jobFlags: addSIDFlags(fs),
+		wait:     fs.Bool("wait", false, "Block until the job is done, then print its final status"),
+		timeout:  fs.Duration("timeout", 10*time.Minute, "With --wait, maximum time to wait for the job (0 for no limit)"),
+	}
+}
+
 func statusCmd(args []string, baseCfg splunk.Config) error {
-	fs := flag.NewFlagSet("status", flag.ExitOnError)
-	sid := fs.String("sid", "", "Search ID (SID) of the job")
+	fs := newCommandFlagSet("status", "status")
+	flags := addStatusFlags(fs)
 	addCommonFlags(fs, &baseCfg)
 	fs.Parse(args)
 
-	if *sid == "" {
-		return errors.New("--sid is a required argument for 'status'")
+	sid, err := flags.jobFlags.resolve("status")
+	if err != nil {
+		return err
 	}
 	if baseCfg.Host == "" {
 		return errors.New("--host is required")
@@ -32,10 +53,45 @@ func statusCmd(args []string, baseCfg splunk.Config) error {
 		printDebugConfig(&baseCfg, client.Log)
 	}
 
-	done, jobState, _, _, err := client.JobStatus(*sid)
-	if err != nil {
-		return err
+	var status *splunk.JobStatus
+	if *flags.wait {
+		status, err = waitForStatusDone(client, sid, *flags.timeout)
+		if status == nil {
+			return err
+		}
+	} else {
+		done, jobState, _, _, statusErr := client.JobStatus(sid)
+		if statusErr != nil {
+			return statusErr
+		}
+		status = &splunk.JobStatus{IsDone: done, DispatchState: jobState}
+	}
+
+	fmt.Printf("SID: %s\nIsDone: %t\nDispatchState: %s", sid, status.IsDone, status.DispatchState)
+	// A job that failed while we waited still gets its status printed above;
+	// the failure is then reported as the command's error.
+	return err
+}
+
+// waitForStatusDone blocks until the job is done and returns its final status.
+// A FAILED job returns its status together with a *splunk.JobFailedError.
+// Unlike run and wait, an interrupt only stops waiting; the job itself is left
+// alone.
+func waitForStatusDone(client *splunk.Client, sid string, timeout time.Duration) (*splunk.JobStatus, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	status, err := client.WaitForJobStatus(ctx, sid)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("job %s did not finish within %v", sid, timeout)
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil, errors.New("waiting for the job was interrupted")
 	}
-	fmt.Printf("SID: %s\nIsDone: %t\nDispatchState: %s", *sid, done, jobState)
-	return nil
+	return status, err
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"splunk_cli/splunk"
+)
+
+// TestStatusWait checks that status --wait prints the final status block from
+// the wait itself, also for a FAILED job, whose failure is returned after it.
+func TestStatusWait(t *testing.T) {
+	for _, state := range []string{"DONE", "FAILED"} {
+		t.Run(state, func(t *testing.T) {
+			fake := newFakeSplunk(t, nil, nil)
+			fake.dispatchState = state
+			fake.messages = []map[string]string{{"type": "FATAL", "text": "Error in 'search' command"}}
+
+			var err error
+			stdout, _ := captureOutput(t, func() {
+				err = statusCmd([]string{"--host", fake.URL, "--token", "zzzzzzzz", "--sid", "fake.1", "--wait"}, splunk.Config{})
+			})
+
+			if want := "SID: fake.1\nIsDone: true\nDispatchState: " + state; stdout != want {
+				t.Errorf("stdout = %q, want %q", stdout, want)
+			}
+			var failed *splunk.JobFailedError
+			if got := errors.As(err, &failed); got != (state == "FAILED") {
+				t.Errorf("err = %v, want JobFailedError: %t", err, state == "FAILED")
+			}
+
+			statusPolls := 0
+			for _, req := range fake.requests {
+				if req == "GET /services/search/jobs/fake.1" {
+					statusPolls++
+				}
+			}
+			if statusPolls != 1 {
+				t.Errorf("job status fetched %d times, want once: %s", statusPolls, strings.Join(fake.requests, ", "))
+			}
+		})
+	}
+}
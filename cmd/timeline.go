@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// timelineFlags holds the flags of the timeline command.
+type timelineFlags struct {
+	jobFlags *sidFlags
+	width    *int
+	format   *string
+}
+
+// addTimelineFlags defines the flags of the timeline command.
+func addTimelineFlags(fs *flag.FlagSet) *timelineFlags {
+	return &timelineFlags{
+		jobFlags: addSIDFlags(fs),
+		width:    fs.Int("width", 50, "Width of the longest histogram bar in characters"),
+		format:   fs.String("format", "text", "Output format: 'text' or 'json'"),
+	}
+}
+
+// timelineCmd prints how a job's events are distributed over its time range
+// as a text histogram, one line per timeline bucket.
+func timelineCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("timeline", "timeline")
+	flags := addTimelineFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if *flags.format != "text" && *flags.format != "json" {
+		return fmt.Errorf("invalid --format '%s': must be 'text' or 'json'", *flags.format)
+	}
+	if *flags.width < 1 {
+		return errors.New("--width must be >= 1")
+	}
+	sid, err := flags.jobFlags.resolve("timeline")
+	if err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	timeline, err := client.Timeline(sid)
+	if err != nil {
+		return err
+	}
+
+	if *flags.format == "json" {
+		out, err := json.MarshalIndent(timeline, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal timeline: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(timeline.Buckets) == 0 {
+		return fmt.Errorf("job %s has no timeline; dispatch it with --status-buckets (e.g. 300)", sid)
+	}
+	peak, countWidth := 0, 1
+	for _, b := range timeline.Buckets {
+		peak = max(peak, b.Count)
+		countWidth = max(countWidth, len(fmt.Sprint(b.Count)))
+	}
+	for _, b := range timeline.Buckets {
+		bar := 0
+		if peak > 0 {
+			bar = (b.Count**flags.width + peak - 1) / peak // any non-empty bucket gets at least one mark
+		}
+		line := fmt.Sprintf("%s  %*d  %s", b.Earliest.Format(time.RFC3339), countWidth, b.Count, strings.Repeat("#", bar))
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	fmt.Printf("Total: %d events in %d buckets\n", timeline.EventCount, len(timeline.Buckets))
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// lastFlagUsage is the help text of the --last flag.
+const lastFlagUsage = "Search a recent period instead of --earliest/--latest: a duration (e.g. '15m', '24h', '7d', '2w') or 'today', 'yesterday', or 'this-week'"
+
+// namedRanges are the --last presets that are not plain durations.
+var namedRanges = map[string][2]string{
+	"today":     {"@d", "now"},
+	"yesterday": {"-1d@d", "@d"},
+	"this-week": {"@w0", "now"},
+}
+
+// parseLast converts a --last value into Splunk earliest and latest time modifiers.
+func parseLast(value string) (earliest, latest string, err error) {
+	value = strings.TrimSpace(value)
+	if r, ok := namedRanges[value]; ok {
+		return r[0], r[1], nil
+	}
+	// Days and weeks are not Go durations, but map directly to Splunk modifiers.
+	if n := len(value); n > 1 && (value[n-1] == 'd' || value[n-1] == 'w') {
+		if count, err := strconv.Atoi(value[:n-1]); err == nil && count > 0 {
+			return fmt.Sprintf("-%d%c", count, value[n-1]), "now", nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Second {
+		return "", "", fmt.Errorf("invalid --last '%s': must be a duration of at least 1s (e.g. '24h', '7d') or 'today', 'yesterday', or 'this-week'", value)
+	}
+	return fmt.Sprintf("-%ds", int64(d/time.Second)), "now", nil
+}
+
+// resolveTimeRange returns the search time range from --earliest/--latest or
+// --last. The configured default time range applies when none of them is given.
+func resolveTimeRange(fs *flag.FlagSet, earliest, latest, last string, cfg *splunk.Config) (string, string, error) {
+	if isFlagSet(fs, "last") {
+		if isFlagSet(fs, "earliest") || isFlagSet(fs, "latest") {
+			return "", "", errors.New("--last cannot be used with --earliest or --latest")
+		}
+		return parseLast(last)
+	}
+	if !isFlagSet(fs, "earliest") {
+		earliest = cfg.DefaultEarliest
+	}
+	if !isFlagSet(fs, "latest") {
+		latest = cfg.DefaultLatest
+	}
+	return earliest, latest, nil
+}
+
+// jobWindow returns the time range Splunk resolved for an earlier job as epoch
+// seconds, so a new search can cover exactly the same period. An open bound is
+// returned empty.
+func jobWindow(client *splunk.Client, sid string) (earliest, latest string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchWindowTimeout)
+	defer cancel()
+	isoEarliest, isoLatest, err := client.SearchWindow(ctx, sid)
+	if err != nil {
+		return "", "", fmt.Errorf("could not read the time window of job %s: %w", sid, err)
+	}
+	if earliest, err = isoToEpoch(isoEarliest); err != nil {
+		return "", "", fmt.Errorf("job %s has an unexpected earliest time: %w", sid, err)
+	}
+	if latest, err = isoToEpoch(isoLatest); err != nil {
+		return "", "", fmt.Errorf("job %s has an unexpected latest time: %w", sid, err)
+	}
+	return earliest, latest, nil
+}
+
+// isoToEpoch converts an ISO 8601 time as reported by Splunk into epoch
+// seconds, with millisecond precision when the time has a fractional part.
+func isoToEpoch(iso string) (string, error) {
+	if iso == "" {
+		return "", nil
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.000-07:00", iso)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339Nano, iso); err != nil {
+			return "", err
+		}
+	}
+	if t.Nanosecond() == 0 {
+		return strconv.FormatInt(t.Unix(), 10), nil
+	}
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', 3, 64), nil
+}
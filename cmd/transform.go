@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rowTransform rewrites a decoded result row into zero or more output rows.
+type rowTransform func(row *resultRow) []*resultRow
+
+// resultRow is a decoded result row that remembers the order of its fields, so
+// a transformed row is encoded with Splunk's field order instead of the sorted
+// order encoding/json gives a map. Fields added by a transform go last.
+type resultRow struct {
+	keys   []string
+	values map[string]any
+}
+
+func newResultRow(size int) *resultRow {
+	return &resultRow{keys: make([]string, 0, size), values: make(map[string]any, size)}
+}
+
+// decodeResultRow decodes a JSON result row, keeping numbers as json.Number.
+func decodeResultRow(raw json.RawMessage) (*resultRow, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("failed to decode result row: expected a JSON object")
+	}
+	row := newResultRow(0)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result row: %w", err)
+		}
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("failed to decode result row: %w", err)
+		}
+		row.set(tok.(string), v)
+	}
+	return row, nil
+}
+
+// set sets field k to v; a new field goes after the existing ones.
+func (r *resultRow) set(k string, v any) {
+	if _, ok := r.values[k]; !ok {
+		r.keys = append(r.keys, k)
+	}
+	r.values[k] = v
+}
+
+func (r *resultRow) remove(k string) {
+	if _, ok := r.values[k]; !ok {
+		return
+	}
+	delete(r.values, k)
+	r.keys = slices.DeleteFunc(r.keys, func(key string) bool { return key == k })
+}
+
+func (r *resultRow) clone() *resultRow {
+	return &resultRow{keys: slices.Clone(r.keys), values: maps.Clone(r.values)}
+}
+
+// MarshalJSON encodes the row with its fields in order.
+func (r *resultRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// applyTransforms runs each result row through the transforms in order. Rows are
+// only decoded when at least one transform is active, so the default output
+// keeps Splunk's original formatting; transformed rows keep its field order.
+func applyTransforms(rows []json.RawMessage, transforms []rowTransform) ([]json.RawMessage, error) {
+	if len(transforms) == 0 {
+		return rows, nil
+	}
+
+	out := make([]json.RawMessage, 0, len(rows))
+	for _, raw := range rows {
+		transformed, err := transformRow(raw, transforms)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, transformed...)
+	}
+	return out, nil
+}
+
+// transformRow runs a single result row through the transforms in order,
+// returning the zero or more rows it becomes. Without transforms, raw is
+// returned untouched.
+func transformRow(raw json.RawMessage, transforms []rowTransform) ([]json.RawMessage, error) {
+	if len(transforms) == 0 {
+		return []json.RawMessage{raw}, nil
+	}
+
+	row, err := decodeResultRow(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []*resultRow{row}
+	for _, transform := range transforms {
+		var next []*resultRow
+		for _, r := range current {
+			next = append(next, transform(r)...)
+		}
+		current = next
+	}
+
+	out := make([]json.RawMessage, 0, len(current))
+	for _, r := range current {
+		encoded, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result row: %w", err)
+		}
+		out = append(out, encoded)
+	}
+	return out, nil
+}
+
+// expandMultivalue handles multivalue fields, which Splunk returns as JSON arrays.
+// With an empty separator, each row is expanded into one row per combination of
+// values. Otherwise the values are joined into a single string with separator.
+// Single-value arrays become plain values and empty arrays become empty strings.
+func expandMultivalue(separator string) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		var mvFields []string
+		for _, k := range row.keys {
+			if _, ok := row.values[k].([]any); ok {
+				mvFields = append(mvFields, k)
+			}
+		}
+		if len(mvFields) == 0 {
+			return []*resultRow{row}
+		}
+		sort.Strings(mvFields)
+
+		if separator != "" {
+			for _, k := range mvFields {
+				values := row.values[k].([]any)
+				parts := make([]string, len(values))
+				for i, v := range values {
+					parts[i] = fmt.Sprint(v)
+				}
+				row.values[k] = strings.Join(parts, separator)
+			}
+			return []*resultRow{row}
+		}
+
+		expanded := []*resultRow{row}
+		for _, k := range mvFields {
+			values := row.values[k].([]any)
+			if len(values) == 0 {
+				values = []any{""}
+			}
+			var next []*resultRow
+			for _, r := range expanded {
+				for _, v := range values {
+					clone := r.clone()
+					clone.values[k] = v
+					next = append(next, clone)
+				}
+			}
+			expanded = next
+		}
+		return expanded
+	}
+}
+
+// flattenRow converts nested objects and arrays in a row into top-level fields
+// keyed by their path, joined with sep ("a.b.c", "arr.0"). Empty objects and
+// arrays are kept as-is under their path so no field disappears. The fields of
+// a nested object take its place in the row, in sorted order.
+func flattenRow(sep string) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		flat := newResultRow(len(row.keys))
+		for _, k := range row.keys {
+			flattenValue(flat, k, row.values[k], sep)
+		}
+		return []*resultRow{flat}
+	}
+}
+
+func flattenValue(flat *resultRow, path string, v any, sep string) {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			flat.set(path, val)
+			return
+		}
+		for _, k := range slices.Sorted(maps.Keys(val)) {
+			flattenValue(flat, path+sep+k, val[k], sep)
+		}
+	case []any:
+		if len(val) == 0 {
+			flat.set(path, val)
+			return
+		}
+		for i, child := range val {
+			flattenValue(flat, path+sep+strconv.Itoa(i), child, sep)
+		}
+	default:
+		flat.set(path, v)
+	}
+}
+
+// splunkTimeLayouts are the layouts in which Splunk renders _time, depending on
+// server and user settings.
+var splunkTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-07:00",
+	"2006-01-02 15:04:05.000 MST",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+// normalizeTime rewrites the named time field as an RFC3339 timestamp in loc.
+// Epoch values and Splunk's formatted timestamps are accepted; rows without the
+// field, or with a value that can't be parsed, are left untouched.
+func normalizeTime(field string, loc *time.Location) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		if t, ok := parseSplunkTime(row.values[field]); ok {
+			row.values[field] = t.In(loc).Format(time.RFC3339Nano)
+		}
+		return []*resultRow{row}
+	}
+}
+
+// parseSplunkTime parses a time value from a decoded result row.
+func parseSplunkTime(v any) (time.Time, bool) {
+	var s string
+	switch val := v.(type) {
+	case json.Number:
+		s = val.String()
+	case string:
+		s = strings.TrimSpace(val)
+	default:
+		return time.Time{}, false
+	}
+
+	if epoch, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(epoch)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)), true
+	}
+	for _, layout := range splunkTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// redactFields removes the given fields from each row.
+func redactFields(fields []string) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		for _, f := range fields {
+			row.remove(f)
+		}
+		return []*resultRow{row}
+	}
+}
+
+// keepFields removes every field except the given ones, which are put in the
+// order given.
+func keepFields(fields []string) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		kept := newResultRow(len(fields))
+		for _, f := range fields {
+			if v, ok := row.values[f]; ok {
+				kept.set(f, v)
+			}
+		}
+		return []*resultRow{kept}
+	}
+}
+
+// hashFields replaces the values of the given fields with the hex-encoded
+// SHA-256 hash of their string form. Each value of a multivalue field is hashed
+// separately; rows without the field are left untouched.
+func hashFields(fields []string) rowTransform {
+	return func(row *resultRow) []*resultRow {
+		for _, f := range fields {
+			switch v := row.values[f].(type) {
+			case nil:
+			case []any:
+				hashed := make([]any, len(v))
+				for i, item := range v {
+					hashed[i] = hashValue(item)
+				}
+				row.values[f] = hashed
+			default:
+				row.values[f] = hashValue(v)
+			}
+		}
+		return []*resultRow{row}
+	}
+}
+
+func hashValue(v any) string {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case json.Number:
+		s = val.String()
+	default:
+		b, _ := json.Marshal(val)
+		s = string(b)
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// transformRows runs JSON rows through transforms and decodes the result.
+func transformRows(t *testing.T, rows []string, transforms ...rowTransform) []map[string]any {
+	t.Helper()
+	raw := make([]json.RawMessage, len(rows))
+	for i, r := range rows {
+		raw[i] = json.RawMessage(r)
+	}
+	out, err := applyTransforms(raw, transforms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := make([]map[string]any, len(out))
+	for i, r := range out {
+		if err := json.Unmarshal(r, &decoded[i]); err != nil {
+			t.Fatalf("output row %d is not JSON: %v: %s", i, err, r)
+		}
+	}
+	return decoded
+}
+
+func TestExpandMultivalue(t *testing.T) {
+	tests := []struct {
+		name      string
+		separator string
+		row       string
+		want      []map[string]any
+	}{
+		{
+			name: "no multivalue fields",
+			row:  `{"host":"a","count":"1"}`,
+			want: []map[string]any{{"host": "a", "count": "1"}},
+		},
+		{
+			name: "single value",
+			row:  `{"host":"a","user":["alice"]}`,
+			want: []map[string]any{{"host": "a", "user": "alice"}},
+		},
+		{
+			name: "empty multivalue",
+			row:  `{"host":"a","user":[]}`,
+			want: []map[string]any{{"host": "a", "user": ""}},
+		},
+		{
+			name: "several values",
+			row:  `{"host":"a","user":["alice","bob"]}`,
+			want: []map[string]any{{"host": "a", "user": "alice"}, {"host": "a", "user": "bob"}},
+		},
+		{
+			name: "combinations",
+			row:  `{"port":["80","443"],"user":["alice","bob"]}`,
+			want: []map[string]any{
+				{"port": "80", "user": "alice"}, {"port": "80", "user": "bob"},
+				{"port": "443", "user": "alice"}, {"port": "443", "user": "bob"},
+			},
+		},
+		{
+			name:      "joined single value",
+			separator: "|",
+			row:       `{"user":["alice"]}`,
+			want:      []map[string]any{{"user": "alice"}},
+		},
+		{
+			name:      "joined empty multivalue",
+			separator: "|",
+			row:       `{"user":[]}`,
+			want:      []map[string]any{{"user": ""}},
+		},
+		{
+			name:      "joined values",
+			separator: "|",
+			row:       `{"user":["alice","bob"]}`,
+			want:      []map[string]any{{"user": "alice|bob"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transformRows(t, []string{tt.row}, expandMultivalue(tt.separator))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTransformsKeepFieldOrder checks that transformed rows keep the field
+// order Splunk returned instead of encoding/json's sorted map order.
+func TestTransformsKeepFieldOrder(t *testing.T) {
+	const row = `{"_time":"1700000000","zone":"eu","host":["a","b"],"count":1,"meta":{"z":1,"a":2}}`
+	tests := []struct {
+		name      string
+		transform rowTransform
+		want      []string
+	}{
+		{
+			name:      "mv separator",
+			transform: expandMultivalue(","),
+			want:      []string{`{"_time":"1700000000","zone":"eu","host":"a,b","count":1,"meta":{"a":2,"z":1}}`},
+		},
+		{
+			name:      "expand multivalue",
+			transform: expandMultivalue(""),
+			want: []string{
+				`{"_time":"1700000000","zone":"eu","host":"a","count":1,"meta":{"a":2,"z":1}}`,
+				`{"_time":"1700000000","zone":"eu","host":"b","count":1,"meta":{"a":2,"z":1}}`,
+			},
+		},
+		{
+			name:      "flatten",
+			transform: flattenRow("."),
+			want:      []string{`{"_time":"1700000000","zone":"eu","host.0":"a","host.1":"b","count":1,"meta.a":2,"meta.z":1}`},
+		},
+		{
+			name:      "redact",
+			transform: redactFields([]string{"host", "meta"}),
+			want:      []string{`{"_time":"1700000000","zone":"eu","count":1}`},
+		},
+		{
+			name:      "keep fields in the given order",
+			transform: keepFields([]string{"count", "zone", "missing"}),
+			want:      []string{`{"count":1,"zone":"eu"}`},
+		},
+		{
+			name:      "normalize time in place",
+			transform: normalizeTime("_time", time.UTC),
+			want:      []string{`{"_time":"2023-11-14T22:13:20Z","zone":"eu","host":["a","b"],"count":1,"meta":{"a":2,"z":1}}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := applyTransforms([]json.RawMessage{json.RawMessage(row)}, []rowTransform{tt.transform})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(out))
+			for i, r := range out {
+				got[i] = string(r)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"splunk_cli/splunk"
+)
+
+// waitFlags holds the flags of the wait command.
+type waitFlags struct {
+	jobFlags       *sidFlags
+	timeout        *time.Duration
+	resultsTimeout *time.Duration
+	silent         *bool
+	onSignal       *string
+	messagesFile   *string
+	counts         *countFlags
+	out            *outputFlags
+}
+
+// addWaitFlags defines the flags of the wait command.
+func addWaitFlags(fs *flag.FlagSet) *waitFlags {
+	return &waitFlags{
+		jobFlags:       addSIDFlags(fs),
+		timeout:        fs.Duration("timeout", 10*time.Minute, "Total timeout for waiting on the job (0 for no limit)"),
+		resultsTimeout: fs.Duration("results-timeout", 10*time.Minute, "Timeout for fetching the results once the job is done (0 for no limit)"),
+		silent:         fs.Bool("silent", false, "Suppress progress messages"),
+		onSignal:       addOnSignalFlag(fs),
+		messagesFile:   addMessagesFileFlag(fs),
+		counts:         addCountFlags(fs),
+		out:            addOutputFlags(fs),
+	}
+}
+
+// waitCmd re-attaches to an existing (e.g. detached) job, waits for it to
+// finish, and prints its results, just like the tail end of 'run'.
+func waitCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("wait", "wait")
+	flags := addWaitFlags(fs)
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if err := flags.out.validate(baseCfg.Debug); err != nil {
+		return err
+	}
+	baseCfg.DebugMaxFieldBytes = *flags.out.maxFieldBytes
+	if err := validateOnSignal(*flags.onSignal); err != nil {
+		return err
+	}
+	if err := flags.counts.validate(); err != nil {
+		return err
+	}
+	sid, err := flags.jobFlags.resolve("wait")
+	if err != nil {
+		return err
+	}
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, *flags.silent)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	status, err := waitForJobInteractive(client, sid, *flags.timeout, *flags.onSignal, nil)
+	if status != nil || err != nil {
+		if msgErr := recordJobMessages(sid, finalJobMessages(status, err), *flags.messagesFile); msgErr != nil && err == nil {
+			err = msgErr
+		}
+	}
+	if err != nil || status == nil {
+		return err
+	}
+	if err := flags.counts.printResults(client, sid, status, baseCfg.Limit, *flags.resultsTimeout, flags.out); err != nil {
+		return err
+	}
+	return flags.counts.check(status.ResultCount)
+}
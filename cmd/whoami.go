@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"splunk_cli/splunk"
+)
+
+// whoamiCmd prints the identity the configured credentials map to.
+func whoamiCmd(args []string, baseCfg splunk.Config) error {
+	fs := newCommandFlagSet("whoami", "whoami")
+	addCommonFlags(fs, &baseCfg)
+	fs.Parse(args)
+
+	if baseCfg.Host == "" {
+		return errors.New("--host is required")
+	}
+	if err := promptForCredentials(&baseCfg); err != nil {
+		return err
+	}
+
+	client, err := splunk.NewClient(&baseCfg, false)
+	if err != nil {
+		return err
+	}
+	if baseCfg.Debug {
+		printDebugConfig(&baseCfg, client.Log)
+	}
+
+	ctx, err := client.CurrentContext()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("User: %s\nRoles: %s\nDefault App: %s\n", ctx.Username, strings.Join(ctx.Roles, ", "), ctx.DefaultApp)
+	return nil
+}
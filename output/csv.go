@@ -0,0 +1,65 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CSVWriter writes rows as CSV with the header fields as columns. Missing
+// values are left empty and non-string values are written in their JSON form.
+// The header row is written with the first row, so an empty result set
+// produces no output.
+type CSVWriter struct {
+	cw     *csv.Writer
+	fields []string
+	record []string
+	wrote  bool
+	rows   int
+}
+
+// flushEvery is how many rows are buffered between flushes.
+const flushEvery = 100
+
+// NewCSVWriter returns a ResultWriter for CSV.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{cw: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) WriteHeader(fields []string) error {
+	c.fields = fields
+	c.record = make([]string, len(fields))
+	return nil
+}
+
+func (c *CSVWriter) WriteRow(row map[string]any) error {
+	if !c.wrote {
+		c.wrote = true
+		if err := c.cw.Write(c.fields); err != nil {
+			return err
+		}
+	}
+	for i, f := range c.fields {
+		c.record[i] = CellValue(row[f])
+	}
+	if err := c.cw.Write(c.record); err != nil {
+		return err
+	}
+	// Flush regularly so a slow or early-exiting reader sees rows as they
+	// are produced, and a closed pipe is noticed without writing everything.
+	c.rows++
+	if c.rows%flushEvery == 0 {
+		c.cw.Flush()
+		return c.cw.Error()
+	}
+	return nil
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (c *CSVWriter) Flush() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+func (c *CSVWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONWriter writes rows as a single {"results": [...]} document, either
+// pretty-printed with two-space indentation or fully compact.
+type JSONWriter struct {
+	w       io.Writer
+	compact bool
+	fields  []string
+	rows    int
+}
+
+// NewJSONWriter returns a ResultWriter for the JSON document format.
+func NewJSONWriter(w io.Writer, compact bool) *JSONWriter {
+	return &JSONWriter{w: w, compact: compact}
+}
+
+func (j *JSONWriter) WriteHeader(fields []string) error {
+	j.fields = fields
+	return nil
+}
+
+func (j *JSONWriter) WriteRow(row map[string]any) error {
+	var encoded []byte
+	var err error
+	if j.compact {
+		encoded, err = json.Marshal(orderedRow{j.fields, row})
+	} else {
+		encoded, err = json.MarshalIndent(orderedRow{j.fields, row}, "    ", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode result row: %w", err)
+	}
+
+	var prefix string
+	switch {
+	case j.rows == 0 && j.compact:
+		prefix = `{"results":[`
+	case j.rows == 0:
+		prefix = "{\n  \"results\": [\n    "
+	case j.compact:
+		prefix = ","
+	default:
+		prefix = ",\n    "
+	}
+	j.rows++
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(encoded)
+	return err
+}
+
+func (j *JSONWriter) Close() error {
+	var suffix string
+	switch {
+	case j.rows == 0 && j.compact:
+		suffix = `{"results":[]}`
+	case j.rows == 0:
+		suffix = "{\n  \"results\": []\n}"
+	case j.compact:
+		suffix = "]}"
+	default:
+		suffix = "\n  ]\n}"
+	}
+	_, err := io.WriteString(j.w, suffix+"\n")
+	return err
+}
+
+// NDJSONWriter writes one compact JSON object per line. An empty result set
+// produces no output.
+type NDJSONWriter struct {
+	w      io.Writer
+	fields []string
+}
+
+// NewNDJSONWriter returns a ResultWriter for newline-delimited JSON.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w}
+}
+
+func (n *NDJSONWriter) WriteHeader(fields []string) error {
+	n.fields = fields
+	return nil
+}
+
+func (n *NDJSONWriter) WriteRow(row map[string]any) error {
+	encoded, err := json.Marshal(orderedRow{n.fields, row})
+	if err != nil {
+		return fmt.Errorf("failed to encode result row: %w", err)
+	}
+	_, err = fmt.Fprintf(n.w, "%s\n", encoded)
+	return err
+}
+
+func (n *NDJSONWriter) Close() error { return nil }
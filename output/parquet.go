@@ -0,0 +1,348 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParquetWriter writes rows as an Apache Parquet file with one column per
+// header field. Parquet stores each column contiguously, so all rows are held
+// in memory and the file is written by Close.
+//
+// Splunk returns every value as a string, so column types are inferred from
+// the values: a column whose values all parse as integers is INT64, one whose
+// values all parse as numbers is DOUBLE, and anything else is a UTF-8 string.
+// Missing values are nulls; empty strings are nulls in numeric columns.
+//
+// The file is uncompressed and has a single row group, which every Parquet
+// reader (pandas, DuckDB, Spark) can load.
+type ParquetWriter struct {
+	w      io.Writer
+	fields []string
+	rows   []map[string]any
+}
+
+// NewParquetWriter returns a ResultWriter for Parquet.
+func NewParquetWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{w: w}
+}
+
+func (p *ParquetWriter) WriteHeader(fields []string) error {
+	p.fields = fields
+	return nil
+}
+
+func (p *ParquetWriter) WriteRow(row map[string]any) error {
+	p.rows = append(p.rows, row)
+	return nil
+}
+
+// Parquet physical types, repetition types, and encodings used by ParquetWriter.
+const (
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetConvertedUTF8 = 0
+)
+
+// parquetMagic starts and ends every Parquet file.
+var parquetMagic = []byte("PAR1")
+
+func (p *ParquetWriter) Close() error {
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+
+	chunks := make([]parquetChunk, len(p.fields))
+	for i, field := range p.fields {
+		values := make([]string, len(p.rows))
+		present := make([]bool, len(p.rows))
+		for j, row := range p.rows {
+			if v, ok := row[field]; ok && v != nil {
+				values[j], present[j] = CellValue(v), true
+			}
+		}
+		typ := inferParquetType(values, present)
+		if typ != parquetByteArray {
+			for j := range values {
+				present[j] = present[j] && values[j] != ""
+			}
+		}
+		page := encodeParquetPage(typ, values, present)
+		chunks[i] = parquetChunk{name: field, typ: typ, offset: int64(file.Len()), size: int64(len(page))}
+		file.Write(page)
+	}
+
+	meta := encodeParquetMetadata(chunks, int64(len(p.rows)))
+	file.Write(meta)
+	binary.Write(&file, binary.LittleEndian, uint32(len(meta)))
+	file.Write(parquetMagic)
+	_, err := p.w.Write(file.Bytes())
+	return err
+}
+
+// parquetChunk describes the column chunk written for one field.
+type parquetChunk struct {
+	name   string
+	typ    int32
+	offset int64 // file offset of the chunk's only data page
+	size   int64 // size of the page, header included
+}
+
+// inferParquetType returns INT64 or DOUBLE if every present, non-empty value
+// is such a number, and BYTE_ARRAY (string) otherwise. Numbers that would not
+// survive the round trip, such as "007" or "+1", keep the column a string.
+func inferParquetType(values []string, present []bool) int32 {
+	typ := int32(parquetInt64)
+	seen := false
+	for i, v := range values {
+		if !present[i] || v == "" {
+			continue
+		}
+		seen = true
+		if !canonicalNumber(v) {
+			return parquetByteArray
+		}
+		if typ == parquetInt64 {
+			if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+				continue
+			}
+			typ = parquetDouble
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return parquetByteArray
+		}
+	}
+	if !seen {
+		return parquetByteArray
+	}
+	return typ
+}
+
+// canonicalNumber reports whether v has no sign or leading zeros that
+// converting it to a number would lose. It does not check that v is a number.
+func canonicalNumber(v string) bool {
+	digits := strings.TrimPrefix(v, "-")
+	if digits == "" || digits[0] == '+' {
+		return false
+	}
+	return len(digits) == 1 || digits[0] != '0' || strings.ContainsRune(".eE", rune(digits[1]))
+}
+
+// encodeParquetPage encodes one uncompressed v1 data page holding all values
+// of a column, preceded by its page header.
+func encodeParquetPage(typ int32, values []string, present []bool) []byte {
+	var data bytes.Buffer
+	levels := encodeDefinitionLevels(present)
+	binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+	data.Write(levels)
+	for i, v := range values {
+		if !present[i] {
+			continue
+		}
+		switch typ {
+		case parquetInt64:
+			n, _ := strconv.ParseInt(v, 10, 64)
+			binary.Write(&data, binary.LittleEndian, n)
+		case parquetDouble:
+			f, _ := strconv.ParseFloat(v, 64)
+			binary.Write(&data, binary.LittleEndian, math.Float64bits(f))
+		default:
+			binary.Write(&data, binary.LittleEndian, uint32(len(v)))
+			data.WriteString(v)
+		}
+	}
+
+	var header thriftWriter
+	header.i32(1, 0) // type: DATA_PAGE
+	header.i32(2, int32(data.Len()))
+	header.i32(3, int32(data.Len()))
+	header.beginStruct(5) // data_page_header
+	header.i32(1, int32(len(values)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.endStruct()
+	header.stop()
+	return append(header.buf.Bytes(), data.Bytes()...)
+}
+
+// encodeDefinitionLevels encodes the definition level of each value (1 if
+// present, 0 if null) with the RLE hybrid encoding and a bit width of 1,
+// as runs of equal levels.
+func encodeDefinitionLevels(present []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		buf = binary.AppendUvarint(buf, uint64(j-i)<<1)
+		if present[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+// encodeParquetMetadata encodes the file footer: the schema and the single
+// row group holding chunks.
+func encodeParquetMetadata(chunks []parquetChunk, numRows int64) []byte {
+	var t thriftWriter
+	t.i32(1, 1) // version
+	t.beginList(2, thriftStruct, len(chunks)+1)
+	t.beginElement()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(chunks)))
+	t.endElement()
+	for _, c := range chunks {
+		t.beginElement()
+		t.i32(1, c.typ)
+		t.i32(3, parquetOptional)
+		t.binary(4, c.name)
+		if c.typ == parquetByteArray {
+			t.i32(6, parquetConvertedUTF8)
+		}
+		t.endElement()
+	}
+	t.i64(3, numRows)
+
+	var totalSize int64
+	for _, c := range chunks {
+		totalSize += c.size
+	}
+	t.beginList(4, thriftStruct, 1)
+	t.beginElement() // row group
+	t.beginList(1, thriftStruct, len(chunks))
+	for _, c := range chunks {
+		t.beginElement()
+		t.i64(2, c.offset)
+		t.beginStruct(3) // meta_data
+		t.i32(1, c.typ)
+		t.beginList(2, thriftI32, 2)
+		t.listI32(parquetPlain)
+		t.listI32(parquetRLE)
+		t.beginList(3, thriftBinary, 1)
+		t.listBinary(c.name)
+		t.i32(4, 0) // codec: UNCOMPRESSED
+		t.i64(5, numRows)
+		t.i64(6, c.size)
+		t.i64(7, c.size)
+		t.i64(9, c.offset)
+		t.endStruct()
+		t.endElement()
+	}
+	t.i64(2, totalSize)
+	t.i64(3, numRows)
+	t.endElement()
+	t.binary(6, "splunk-cli")
+	t.stop()
+	return t.buf.Bytes()
+}
+
+// Thrift compact protocol type codes used in the Parquet metadata.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, the
+// serialization of Parquet page headers and file metadata. Fields must be
+// written in increasing id order within each struct.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+	stack  []int16 // lastID of the enclosing structs
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.lastID = id
+}
+
+// varint writes n zigzag-encoded, as the compact protocol does for integers.
+func (t *thriftWriter) varint(n int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(n<<1^n>>63)))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginElement()
+}
+
+func (t *thriftWriter) endStruct() {
+	t.endElement()
+}
+
+func (t *thriftWriter) beginList(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+	}
+}
+
+// beginElement starts a struct, either a list element or a field value.
+func (t *thriftWriter) beginElement() {
+	t.stack = append(t.stack, t.lastID)
+	t.lastID = 0
+}
+
+// endElement ends the struct started by beginElement.
+func (t *thriftWriter) endElement() {
+	t.stop()
+	t.lastID = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
+
+// stop ends the fields of the current struct.
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
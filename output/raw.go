@@ -0,0 +1,31 @@
+package output
+
+import (
+	"io"
+	"strings"
+)
+
+// RawWriter writes the _raw field of each row followed by a newline, for
+// log-style output. Rows without _raw are skipped.
+type RawWriter struct {
+	w io.Writer
+}
+
+// NewRawWriter returns a ResultWriter that prints raw events.
+func NewRawWriter(w io.Writer) *RawWriter {
+	return &RawWriter{w: w}
+}
+
+func (r *RawWriter) WriteHeader(fields []string) error { return nil }
+
+func (r *RawWriter) WriteRow(row map[string]any) error {
+	v, ok := row["_raw"]
+	if !ok || v == nil {
+		return nil
+	}
+	raw := strings.TrimRight(CellValue(v), "\r\n")
+	_, err := io.WriteString(r.w, raw+"\n")
+	return err
+}
+
+func (r *RawWriter) Close() error { return nil }
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// TableWriter writes rows as an aligned text table with the header fields as
+// columns. Fields outside the columns are dropped and missing values are left
+// blank. An empty result set produces no output.
+type TableWriter struct {
+	tw     *tabwriter.Writer
+	fields []string
+	cells  []string
+	wrote  bool
+}
+
+// NewTableWriter returns a ResultWriter for aligned text tables.
+func NewTableWriter(w io.Writer) *TableWriter {
+	return &TableWriter{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+}
+
+func (t *TableWriter) WriteHeader(fields []string) error {
+	t.fields = fields
+	t.cells = make([]string, len(fields))
+	return nil
+}
+
+func (t *TableWriter) WriteRow(row map[string]any) error {
+	if !t.wrote {
+		t.wrote = true
+		if _, err := fmt.Fprintln(t.tw, strings.Join(t.fields, "\t")); err != nil {
+			return err
+		}
+	}
+	for i, f := range t.fields {
+		t.cells[i] = tableCell(CellValue(row[f]))
+	}
+	_, err := fmt.Fprintln(t.tw, strings.Join(t.cells, "\t"))
+	return err
+}
+
+func (t *TableWriter) Close() error {
+	return t.tw.Flush()
+}
+
+// tableCell keeps a value on one line so it cannot break the table layout.
+func tableCell(s string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
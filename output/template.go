@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateWriter renders each row with a text/template and writes the result
+// followed by a newline. The row is the template's dot, so '{{.host}}' is the
+// host field. A plain field reference such as '{{.name}}' to a field that is
+// missing from a row, or null, renders as an empty string instead of
+// text/template's "<no value>".
+type TemplateWriter struct {
+	w      io.Writer
+	tmpl   *template.Template
+	fields []string // plain fields the template refers to
+}
+
+// ParseRowTemplate parses text as a row template for NewTemplateWriter, so
+// that syntax errors can be reported before any results are fetched.
+func ParseRowTemplate(text string) (*template.Template, error) {
+	return template.New("row").Parse(text)
+}
+
+// NewTemplateWriter returns a ResultWriter that renders rows with tmpl.
+func NewTemplateWriter(w io.Writer, tmpl *template.Template) *TemplateWriter {
+	seen := map[string]bool{}
+	var fields []string
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		walkTemplateFields(t.Tree.Root, func(name string) {
+			if !seen[name] {
+				seen[name] = true
+				fields = append(fields, name)
+			}
+		})
+	}
+	return &TemplateWriter{w: w, tmpl: tmpl, fields: fields}
+}
+
+func (t *TemplateWriter) WriteHeader(fields []string) error { return nil }
+
+func (t *TemplateWriter) WriteRow(row map[string]any) error {
+	dot := make(map[string]any, len(row)+len(t.fields))
+	for _, f := range t.fields {
+		dot[f] = ""
+	}
+	for k, v := range row {
+		if v != nil {
+			dot[k] = v
+		}
+	}
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, dot); err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := t.w.Write(buf.Bytes())
+	return err
+}
+
+func (t *TemplateWriter) Close() error { return nil }
+
+// walkTemplateFields calls fn with the name of every plain field reference
+// ('.name') below node. References inside range and with blocks are included
+// even though their dot is different; giving those names a default in the row
+// is harmless. Nested references ('.name.sub') are left alone, since an empty
+// string has no fields.
+func walkTemplateFields(node parse.Node, fn func(name string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateFields(child, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateFields(n.Pipe, fn)
+	case *parse.TemplateNode:
+		walkTemplateFields(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranchFields(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranchFields(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranchFields(&n.BranchNode, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkTemplateFields(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplateFields(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkTemplateFields(n.Node, fn)
+	case *parse.FieldNode:
+		if len(n.Ident) == 1 {
+			fn(n.Ident[0])
+		}
+	}
+}
+
+func walkBranchFields(n *parse.BranchNode, fn func(name string)) {
+	walkTemplateFields(n.Pipe, fn)
+	walkTemplateFields(n.List, fn)
+	walkTemplateFields(n.ElseList, fn)
+}
@@ -0,0 +1,90 @@
+// Package output renders search result rows in the formats supported by
+// splunk-cli. Every format implements ResultWriter, so callers can plug in
+// their own formats without touching the Splunk client.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ResultWriter writes a stream of result rows in one output format.
+//
+// WriteHeader is called before any row with the field names in the order they
+// should appear. Tabular formats use them as their columns and ignore other
+// fields; JSON formats use them to order the keys of each row, so a streaming
+// caller may call WriteHeader on them again between rows with a list that
+// extends the previous one. WriteRow is called once per row, and Close
+// finishes the document. A writer does not close the underlying io.Writer.
+type ResultWriter interface {
+	WriteHeader(fields []string) error
+	WriteRow(row map[string]any) error
+	Close() error
+}
+
+// orderedRow marshals a row with its keys in the order of fields, followed by
+// any remaining keys in sorted order.
+type orderedRow struct {
+	fields []string
+	row    map[string]any
+}
+
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	keys := make([]string, 0, len(r.row))
+	listed := make(map[string]bool, len(r.fields))
+	for _, f := range r.fields {
+		if _, ok := r.row[f]; ok && !listed[f] {
+			listed[f] = true
+			keys = append(keys, f)
+		}
+	}
+	var rest []string
+	for k := range r.row {
+		if !listed[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.row[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// CellValue renders a field value as a single text cell: strings as-is,
+// missing values as empty, and anything else in its JSON form.
+func CellValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
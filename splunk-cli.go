@@ -22,5 +22,5 @@ func main() {
 		}
 	}
 
-	cmd.Execute()
+	cmd.Execute(Version)
 }
\ No newline at end of file
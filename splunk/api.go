@@ -0,0 +1,60 @@
+package splunk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DoAPI sends a request to an arbitrary REST endpoint and returns the response
+// body. pathSegments are resolved like the client's own endpoints, in the
+// configured app namespace, unless they start with "services" or "servicesNS",
+// in which case they are used as-is. params are sent in the query string, or
+// as a form-encoded body for POST. A non-2xx response yields an *APIError.
+func (c *Client) DoAPI(ctx context.Context, method string, pathSegments []string, params url.Values) ([]byte, error) {
+	method = strings.ToUpper(method)
+	var endpoint string
+	var err error
+	if len(pathSegments) > 0 && (pathSegments[0] == "services" || pathSegments[0] == "servicesNS") {
+		endpoint, err = c.joinHostPath(pathSegments...)
+	} else {
+		endpoint, err = c.createAPIURL(pathSegments...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: %s %s
+`, method, endpoint)
+
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req.URL.RawQuery = params.Encode()
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !isSuccess(resp.StatusCode) {
+		return nil, newAPIError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,79 @@
+package splunk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// CurrentContext describes the identity the client is authenticated as.
+type CurrentContext struct {
+	Username   string   `json:"username"`
+	Roles      []string `json:"roles"`
+	DefaultApp string   `json:"defaultApp"`
+	// Capabilities are the capabilities granted through the roles. Some
+	// Splunk versions do not report them, in which case the list is empty.
+	Capabilities []string `json:"capabilities"`
+}
+
+// HasCapability reports whether the context grants the named capability.
+func (c *CurrentContext) HasCapability(name string) bool {
+	for _, capability := range c.Capabilities {
+		if capability == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Ping verifies connectivity and authentication by querying the identity of the
+// authenticated user. It returns the username on success.
+func (c *Client) Ping() (string, error) {
+	ctx, err := c.CurrentContext()
+	if err != nil {
+		return "", err
+	}
+	return ctx.Username, nil
+}
+
+// CurrentContext returns the authenticated user's name, roles, and default app.
+func (c *Client) CurrentContext() (*CurrentContext, error) {
+	endpoint, err := c.createServicesURL("authentication", "current-context")
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Entry []struct {
+			Content CurrentContext `json:"content"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode current context response: %w", err)
+	}
+	if len(page.Entry) == 0 {
+		return nil, errors.New("current context not found in response")
+	}
+	return &page.Entry[0].Content, nil
+}
@@ -1,19 +1,30 @@
 package splunk
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	mathrand "math/rand/v2"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // Client holds the state for a command execution, including the HTTP client.
@@ -21,32 +32,66 @@ type Client struct {
 	client *http.Client
 	cfg    *Config
 	Log    *Logger
+
+	insecureWarning sync.Once
+
+	doneJobsMu sync.Mutex
+	doneJobs   map[string]cachedJobContent // Recently seen finished jobs, see doneJobContent
 }
 
 // Logger provides a simple logger that can be silenced.
+// Progress and debug messages are never written to stdout, which is reserved
+// for result payloads; they go to Out, or os.Stderr when Out is nil.
 type Logger struct {
+	Out    io.Writer
 	silent bool
 	debug  bool
 }
 
+func (l *Logger) writer() io.Writer {
+	if l.Out == nil {
+		return os.Stderr
+	}
+	return l.Out
+}
+
 func (l *Logger) Printf(format string, a ...any) {
 	if !l.silent {
-		fmt.Fprintf(os.Stderr, format, a...)
+		fmt.Fprintf(l.writer(), format, a...)
 	}
 }
 
 func (l *Logger) Println(a ...any) {
 	if !l.silent {
-		fmt.Fprintln(os.Stderr, a...)
+		fmt.Fprintln(l.writer(), a...)
 	}
 }
 
 func (l *Logger) Debugf(format string, a ...any) {
 	if l.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: "+format, a...)
+		fmt.Fprintf(l.writer(), "DEBUG: "+format, a...)
+	}
+}
+
+// verifyChainOnly verifies the server's certificate chain against the system
+// roots, like the default TLS verification, but without matching the host name.
+func verifyChainOnly(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: server presented no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
 	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+	})
+	return err
 }
 
+// defaultMaxIdleConns is the number of idle connections kept open to the Splunk host.
+const defaultMaxIdleConns = 10
+
 // NewClient creates a new state object, including the HTTP client with a proper cookie jar.
 func NewClient(cfg *Config, silent bool) (*Client, error) {
 	jar, err := cookiejar.New(nil)
@@ -54,8 +99,30 @@ func NewClient(cfg *Config, silent bool) (*Client, error) {
 		return nil, fmt.Errorf("fatal: could not create cookie jar: %w", err)
 	}
 
+	if cfg.MaxIdleConns < 0 || cfg.MaxConnsPerHost < 0 {
+		return nil, fmt.Errorf("connection pool limits must be >= 0")
+	}
+	if cfg.PollJitter < 0 || cfg.PollJitter >= 1 {
+		return nil, fmt.Errorf("poll jitter must be >= 0 and < 1")
+	}
+
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: cfg.Insecure}
+	if cfg.SkipHostnameVerify && !cfg.Insecure {
+		// Go's built-in verification always checks the host name, so it is
+		// replaced by a chain-only verification.
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		transport.TLSClientConfig.VerifyConnection = verifyChainOnly
+	}
+	// All requests go to a single Splunk host, so the per-host idle pool is the
+	// one that matters; the default transport keeps only 2 idle connections.
+	idle := cfg.MaxIdleConns
+	if idle == 0 {
+		idle = defaultMaxIdleConns
+	}
+	transport.MaxIdleConns = idle
+	transport.MaxIdleConnsPerHost = idle
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost // 0 means no limit
 
 	client := &http.Client{
 		Transport: transport,
@@ -71,28 +138,40 @@ func NewClient(cfg *Config, silent bool) (*Client, error) {
 }
 
 func (c *Client) createAPIURL(pathSegments ...string) (string, error) {
-	baseURL, err := url.Parse(c.cfg.Host)
-	if err != nil {
-		return "", fmt.Errorf("invalid host URL in configuration: %w", err)
-	}
-
-	var finalSegments []string
 	if c.cfg.App != "" {
 		owner := c.cfg.Owner
 		if owner == "" {
 			owner = "nobody"
 		}
-		finalSegments = append([]string{"servicesNS", owner, c.cfg.App}, pathSegments...)
-	} else {
-		finalSegments = append([]string{"services"}, pathSegments...)
+		return c.createNamespacedAPIURL(owner, c.cfg.App, pathSegments...)
 	}
+	return c.createServicesURL(pathSegments...)
+}
 
-	fullURL := baseURL.JoinPath(finalSegments...)
-	return fullURL.String(), nil
+// createServicesURL builds a non-namespaced /services URL, regardless of the
+// configured app. It is used for server-wide endpoints.
+func (c *Client) createServicesURL(pathSegments ...string) (string, error) {
+	return c.joinHostPath(append([]string{"services"}, pathSegments...)...)
+}
+
+// createNamespacedAPIURL builds a servicesNS URL for an explicit owner and app,
+// which may be "-" to match all namespaces.
+func (c *Client) createNamespacedAPIURL(owner, app string, pathSegments ...string) (string, error) {
+	return c.joinHostPath(append([]string{"servicesNS", owner, app}, pathSegments...)...)
+}
+
+func (c *Client) joinHostPath(segments ...string) (string, error) {
+	baseURL, err := url.Parse(c.cfg.Host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host URL in configuration: %w", err)
+	}
+	return baseURL.JoinPath(segments...).String(), nil
 }
 
 func (c *Client) handleFailedResponse(resp *http.Response, expectedStatus int) error {
-	if resp.StatusCode == expectedStatus {
+	// A web UI port answers REST paths with an HTML page, sometimes with the
+	// expected status; report that instead of a JSON decoding error.
+	if resp.StatusCode == expectedStatus && !isHTMLContentType(resp) {
 		return nil
 	}
 
@@ -101,12 +180,214 @@ func (c *Client) handleFailedResponse(resp *http.Response, expectedStatus int) e
 `)
 		for k, v := range resp.Header {
 			c.Log.Debugf(`  %s: %s
-`, k, strings.Join(v, ", "))
+`, k, truncateLines(strings.Join(v, ", "), c.cfg.DebugMaxFieldBytes))
 		}
 	}
 
+	return newAPIError(resp)
+}
+
+// APIError is returned when the Splunk REST API answers with an unexpected status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	RequestID  string
+	Body       string
+	// HTML is set when the response was an HTML page, typically because the
+	// host points at the Splunk Web port rather than the management port.
+	HTML bool
+}
+
+func (e *APIError) Error() string {
+	if e.HTML {
+		return fmt.Sprintf(`received HTML, not a REST response (status %s, request ID: %s) — are you using the management port (usually :8089)?`, e.Status, e.RequestID)
+	}
+	return fmt.Sprintf(`API request failed with status %s (request ID: %s). Response: %s`, e.Status, e.RequestID, e.Body)
+}
+
+// JobNotFoundError is returned by the job endpoints when Splunk answers 404
+// Not Found: the SID is wrong, or the job has expired after its TTL.
+type JobNotFoundError struct {
+	SID      string
+	APIError *APIError
+}
+
+func (e *JobNotFoundError) Error() string {
+	return fmt.Sprintf("job %s not found (expired or invalid SID)", e.SID)
+}
+
+func (e *JobNotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// jobError turns a 404 APIError from an endpoint of job sid into a
+// JobNotFoundError; any other error is returned unchanged.
+func jobError(sid string, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return &JobNotFoundError{SID: sid, APIError: apiErr}
+	}
+	return err
+}
+
+// looksLikeHTML reports whether body is markup other than a Splunk XML
+// response, which some endpoints return for errors despite output_mode=json.
+func looksLikeHTML(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return bytes.HasPrefix(trimmed, []byte("<")) &&
+		!bytes.HasPrefix(trimmed, []byte("<?xml")) &&
+		!bytes.HasPrefix(trimmed, []byte("<response"))
+}
+
+// isHTMLContentType reports whether resp declares an HTML body.
+func isHTMLContentType(resp *http.Response) bool {
+	return strings.HasPrefix(strings.ToLower(resp.Header.Get("Content-Type")), "text/html")
+}
+
+// newAPIError builds an APIError from resp, consuming its body.
+func newAPIError(resp *http.Response) *APIError {
 	body, _ := io.ReadAll(resp.Body)
-	return fmt.Errorf(`API request failed with status %s. Response: %s`, resp.Status, string(body))
+	body = decodeErrorBody(resp.Header.Get("Content-Encoding"), body)
+	requestID := ""
+	if resp.Request != nil {
+		requestID = resp.Request.Header.Get(requestIDHeader)
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RequestID:  requestID,
+		Body:       string(body),
+		HTML:       isHTMLContentType(resp) || looksLikeHTML(body),
+	}
+}
+
+// decodeErrorBody decompresses an error body sent with a gzip or deflate
+// Content-Encoding. Go's transport only does this itself when it chose the
+// Accept-Encoding header, which a custom header (--header) overrides. A body
+// that cannot be decompressed is returned unchanged.
+func decodeErrorBody(encoding string, body []byte) []byte {
+	var r io.Reader
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// Servers disagree on whether deflate means zlib-wrapped or raw data.
+		if r, err = zlib.NewReader(bytes.NewReader(body)); err != nil {
+			r, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	default:
+		return body
+	}
+	if err != nil {
+		return body
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// isSuccess reports whether status is in the 2xx range.
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
+
+const requestIDHeader = "X-Request-ID"
+
+// newRequestID generates a random (version 4) UUID for correlating requests with server-side logs.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// minMaskedSecretLen is the length from which MaskSecret keeps the last 4
+// characters of a secret.
+const minMaskedSecretLen = 12
+
+// MaskSecret hides a credential for display. Secrets of 12 or more characters
+// keep their last 4 characters ("****abcd"); shorter ones are masked entirely,
+// since a 4-character suffix would reveal too much of them.
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < minMaskedSecretLen {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// TruncateField cuts s to at most max bytes, backing off to a UTF-8 character
+// boundary, and appends an ellipsis with the original length.
+func TruncateField(s string, max int) string {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... [%d bytes]", s[:cut], len(s))
+}
+
+// truncateLines applies TruncateField to every line of s longer than max
+// bytes. A max of 0 leaves s alone.
+func truncateLines(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if len(line) > max {
+			lines[i] = TruncateField(line, max)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// defaultSensitiveHeaders are always masked in debug output.
+var defaultSensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// IsSensitiveHeader reports whether the value of the named header is masked in
+// debug output: one of the default credential headers, or a name in extra.
+func IsSensitiveHeader(name string, extra []string) bool {
+	for _, list := range [][]string{defaultSensitiveHeaders, extra} {
+		for _, s := range list {
+			if strings.EqualFold(name, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maskHeaders masks sensitive header values in an HTTP dump. The credentials
+// of an Authorization header are masked after its scheme, whether a bearer
+// token, basic credentials, or a session key; the whole value of any other
+// sensitive header is masked.
+func maskHeaders(dump string, extra []string) string {
+	lines := strings.Split(dump, "\r\n")
+	for i, line := range lines {
+		if line == "" {
+			break // end of the headers
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !IsSensitiveHeader(name, extra) {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.EqualFold(name, "Authorization") {
+			scheme, credentials, _ := strings.Cut(value, " ")
+			lines[i] = name + ": " + scheme + " " + MaskSecret(credentials)
+		} else {
+			lines[i] = name + ": " + MaskSecret(value)
+		}
+	}
+	return strings.Join(lines, "\r\n")
 }
 
 func (c *Client) setupAuth(req *http.Request) error {
@@ -119,9 +400,27 @@ func (c *Client) setupAuth(req *http.Request) error {
 }
 
 func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.cfg.Insecure {
+		c.insecureWarning.Do(func() {
+			c.Log.Printf("WARNING: TLS certificate verification is disabled (--insecure); the identity of %s is not verified.\n", req.URL.Host)
+		})
+	}
 	if err := c.setupAuth(req); err != nil {
 		return nil, err
 	}
+	requestID := c.cfg.RequestID
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	req.Header.Set(requestIDHeader, requestID)
+	if c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+	// Extra headers come last, so an explicitly configured header (even
+	// Authorization) takes precedence over the computed one.
+	for name, value := range c.cfg.Headers {
+		req.Header.Set(name, value)
+	}
 
 	if c.Log.debug {
 		dump, err := httputil.DumpRequestOut(req, true)
@@ -129,10 +428,15 @@ func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
 			c.Log.Debugf(`Error dumping request: %v
 `, err)
 		} else {
-			dumpStr := string(dump)
-			if c.cfg.Token != "" {
-				dumpStr = strings.Replace(dumpStr, c.cfg.Token, "<TOKEN>", 1)
+			dumpStr := maskHeaders(string(dump), c.cfg.SensitiveHeaders)
+			// maskHeaders covers the Authorization header. A token that is
+			// long enough to be unambiguous is also masked wherever else it
+			// appears; a short one would mask unrelated text in the URL,
+			// the form body, or the SPL.
+			if len(c.cfg.Token) >= minMaskedSecretLen {
+				dumpStr = strings.ReplaceAll(dumpStr, c.cfg.Token, MaskSecret(c.cfg.Token))
 			}
+			dumpStr = truncateLines(dumpStr, c.cfg.DebugMaxFieldBytes)
 			c.Log.Debugf(
 				`
 --- BEGIN HTTP REQUEST DUMP ---
@@ -144,74 +448,343 @@ func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	return c.client.Do(req)
+	return c.sendWithRetry(req)
 }
 
-// StartSearch initiates a search job on Splunk.
-func (c *Client) StartSearch(spl, earliest, latest string) (string, error) {
-	endpoint, err := c.createAPIURL("search", "jobs")
-	if err != nil {
-		return "", err
+// SearchOptions holds the optional dispatch parameters for StartSearch.
+type SearchOptions struct {
+	Earliest string
+	Latest   string
+	// AutoCancel and AutoPause are the seconds of inactivity (no client polling)
+	// after which Splunk cancels or pauses the job. Zero leaves them unset.
+	AutoCancel int
+	AutoPause  int
+	// Label and Custom tag the job with custom.* metadata so it can be
+	// recognized in Splunk's job manager and in ListJobs.
+	Label  string
+	Custom map[string]string
+	// TimeFormat is the strptime-style format of formatted Earliest/Latest
+	// values. When empty, numeric (epoch) values are detected automatically.
+	TimeFormat string
+	// ReuseMaxAge lets Splunk return an existing job for an identical search
+	// dispatched within this period instead of starting a new one. Zero
+	// always starts a new job.
+	ReuseMaxAge time.Duration
+	// ID is the SID to create the job with. When empty and retries are
+	// enabled, StartSearch generates one so that a retried request cannot
+	// create a duplicate job.
+	ID string
+	// Params are extra form fields for the job-creation endpoint, for
+	// parameters without a dedicated option (e.g. max_time, status_buckets).
+	// Fields set by the other options take precedence.
+	Params map[string]string
+	// CompressRequest gzips the job-creation request when its body is at
+	// least 64 KiB. StartSearch resends it uncompressed if the server answers
+	// 415 Unsupported Media Type.
+	CompressRequest bool
+	// NoSpawn runs the search inside the search head's main process
+	// (spawn_process=false) instead of a separate search process. When false
+	// the parameter is omitted and Splunk decides.
+	NoSpawn bool
+	// StatusBuckets is the number of timeline buckets Splunk keeps for the
+	// job (status_buckets), which Timeline needs. Zero leaves it unset.
+	StatusBuckets int
+	// SampleEvents makes Splunk finalize the job once it has scanned this
+	// many events (auto_finalize_ec), for quick approximate results over
+	// large indexes. Zero leaves it unset.
+	SampleEvents int
+}
+
+// epochTimeFormats returns the earliest/latest values and the time_format to
+// send so that epoch values are not misread as formatted time strings. Relative
+// modifiers such as "-1h" or "now" are unaffected by time_format. If any epoch
+// value has a fractional part, all epoch values are sent with millisecond
+// precision using "%s.%Q".
+func epochTimeFormats(earliest, latest string) (string, string, string) {
+	isEpoch := func(v string) (float64, bool) {
+		if v == "" || strings.ContainsAny(v, "eE") {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil && f >= 0
 	}
-	c.Log.Debugf(`Request: POST %s
-`, endpoint)
+	e, eEpoch := isEpoch(earliest)
+	l, lEpoch := isEpoch(latest)
+	if !eEpoch && !lEpoch {
+		return earliest, latest, ""
+	}
+	if !strings.Contains(earliest, ".") && !strings.Contains(latest, ".") {
+		return earliest, latest, "%s"
+	}
+	millis := func(v string, f float64, ok bool) string {
+		if !ok {
+			return v
+		}
+		return strconv.FormatFloat(f, 'f', 3, 64)
+	}
+	return millis(earliest, e, eEpoch), millis(latest, l, lEpoch), "%s.%Q"
+}
 
+// searchForm returns the form fields that describe the search spl with opts,
+// shared by the job-creation and export endpoints. SPL that does not start
+// with a generating command ('|') is prefixed with the implicit "search".
+func searchForm(spl string, opts SearchOptions) url.Values {
 	form := url.Values{}
+	for k, v := range opts.Params {
+		form.Set(k, v)
+	}
 	if !strings.HasPrefix(strings.TrimSpace(spl), "|") {
 		form.Set("search", "search "+spl)
 	} else {
 		form.Set("search", spl)
 	}
+	earliest, latest, timeFormat := opts.Earliest, opts.Latest, opts.TimeFormat
+	if timeFormat == "" {
+		earliest, latest, timeFormat = epochTimeFormats(earliest, latest)
+	}
 	if earliest != "" {
 		form.Set("earliest_time", earliest)
 	}
 	if latest != "" {
 		form.Set("latest_time", latest)
 	}
-	form.Set("output_mode", "json")
+	if timeFormat != "" {
+		form.Set("time_format", timeFormat)
+	}
+	if opts.AutoCancel > 0 {
+		form.Set("auto_cancel", strconv.Itoa(opts.AutoCancel))
+	}
+	if opts.AutoPause > 0 {
+		form.Set("auto_pause", strconv.Itoa(opts.AutoPause))
+	}
+	for k, v := range opts.Custom {
+		form.Set("custom."+k, v)
+	}
+	if opts.Label != "" {
+		form.Set("custom.label", opts.Label)
+	}
+	if opts.NoSpawn {
+		form.Set("spawn_process", "false")
+	}
+	if opts.StatusBuckets > 0 {
+		form.Set("status_buckets", strconv.Itoa(opts.StatusBuckets))
+	}
+	if opts.SampleEvents > 0 {
+		form.Set("auto_finalize_ec", strconv.Itoa(opts.SampleEvents))
+	}
+	if opts.ReuseMaxAge > 0 {
+		form.Set("reuse_max_seconds_ago", strconv.Itoa(int(opts.ReuseMaxAge.Seconds())))
+	}
+	return form
+}
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+// StartSearch initiates a search job on Splunk and returns its SID. reused is
+// true when opts.ReuseMaxAge is set and Splunk returned an existing job.
+func (c *Client) StartSearch(spl string, opts SearchOptions) (sid string, reused bool, err error) {
+	endpoint, err := c.createAPIURL("search", "jobs")
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Log.Debugf(`Request: POST %s
+`, endpoint)
 
-	resp, err := c.doRequest(req)
+	form := searchForm(spl, opts)
+	id, generatedID := opts.ID, false
+	if id == "" && c.cfg.Retries > 0 {
+		id, generatedID = newRequestID(), true
+		c.Log.Debugf("Using generated job id %s so that retries cannot create duplicate jobs\n", id)
+	}
+	if id != "" {
+		form.Set("id", id)
+	}
+	form.Set("output_mode", "json")
+
+	body := form.Encode()
+	compress := opts.CompressRequest && len(body) >= compressRequestThreshold
+	resp, err := c.postSearchForm(endpoint, body, id != "", compress)
 	if err != nil {
-		return "", err
+		return "", false, err
+	}
+	if compress && resp.StatusCode == http.StatusUnsupportedMediaType {
+		resp.Body.Close()
+		c.Log.Debugf("Server does not accept a gzip-encoded request body (HTTP 415); resending uncompressed\n")
+		if resp, err = c.postSearchForm(endpoint, body, id != "", false); err != nil {
+			return "", false, err
+		}
 	}
 	defer resp.Body.Close()
 
-	if err := c.handleFailedResponse(resp, http.StatusCreated); err != nil {
-		return "", err
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		return "", false, fmt.Errorf("search request of %d bytes was rejected as too large (HTTP 413) by the server or a proxy in between; shorten the SPL or raise the upload limit", len(body))
+	}
+	// A new job is answered with 201 Created; a reused one with 200 OK.
+	reused = opts.ReuseMaxAge > 0 && resp.StatusCode == http.StatusOK && !isHTMLContentType(resp)
+	if !reused {
+		if err := c.handleFailedResponse(resp, http.StatusCreated); err != nil {
+			// Splunk rejects a duplicate id. For an id we generated, that means
+			// an earlier attempt that looked failed did create the job.
+			if generatedID {
+				if _, statusErr := c.fetchJobContent(context.Background(), id); statusErr == nil {
+					c.Log.Debugf("Job %s was already created by an earlier attempt\n", id)
+					return id, false, nil
+				}
+			}
+			return "", false, err
+		}
 	}
 
 	var job struct {
 		SID string `json:"sid"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
-		return "", err
+		return "", false, err
 	}
-	return job.SID, nil
+	return job.SID, reused, nil
 }
 
+// compressRequestThreshold is the form body size from which StartSearch
+// gzips the request when SearchOptions.CompressRequest is set. Smaller bodies
+// gain too little to be worth it.
+const compressRequestThreshold = 64 * 1024
+
+// postSearchForm posts the form-encoded job-creation body to endpoint. The SPL
+// is always sent in the body, never in the URL, with an explicit
+// Content-Length so that proxies which reject chunked uploads still accept
+// very long, machine-generated searches. With compress, the body is sent
+// gzip-encoded. idempotent marks a request with a fixed SID, which cannot
+// start a second job and so is safe to retry even after a network error.
+func (c *Client) postSearchForm(endpoint, body string, idempotent, compress bool) (*http.Response, error) {
+	payload := []byte(body)
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, fmt.Errorf("failed to compress search request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress search request: %w", err)
+		}
+		c.Log.Debugf("Compressed search request from %d to %d bytes\n", len(payload), buf.Len())
+		payload = buf.Bytes()
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if idempotent {
+		req = req.WithContext(withIdempotent(req.Context()))
+	}
+	return c.doRequest(req)
+}
+
+// ErrJobStatusNotFound is returned by JobStatus when the response contains no job
+// entry, which can happen briefly right after a job is created.
+var ErrJobStatusNotFound = errors.New("job status not found in response")
+
+// jobStatusGracePolls is how many consecutive empty status responses WaitForJob
+// tolerates before the job has been seen for the first time.
+const jobStatusGracePolls = 5
+
 type SplunkMessage struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
 }
 
+// partialResultMarkers are substrings of job messages Splunk emits when one or
+// more search peers failed and the results may be incomplete.
+var partialResultMarkers = []string{
+	"might be incomplete",
+	"may be incomplete",
+	"ended prematurely",
+	"peer failed",
+	"peers failed",
+	"unable to distribute to peer",
+	"search_factor",
+}
+
+// PartialResultMessages returns the WARN/ERROR messages that indicate a job's
+// results are incomplete because some search peers failed.
+func PartialResultMessages(messages []SplunkMessage) []SplunkMessage {
+	var partial []SplunkMessage
+	for _, msg := range messages {
+		msgType := strings.ToUpper(msg.Type)
+		if msgType != "WARN" && msgType != "ERROR" && msgType != "FATAL" {
+			continue
+		}
+		text := strings.ToLower(msg.Text)
+		for _, marker := range partialResultMarkers {
+			if strings.Contains(text, marker) {
+				partial = append(partial, msg)
+				break
+			}
+		}
+	}
+	return partial
+}
+
+// peerNamePattern matches the peer a partial-results message refers to, as in
+// "on peer idx1", "peer named idx2" or "peer=idx3".
+var peerNamePattern = regexp.MustCompile(`(?i)\bpeer(?:\s+named|\s*=|\s)\s*([A-Za-z0-9][\w.:-]*)`)
+
+// FailedPeers returns the distinct search peer names mentioned in messages, in
+// the order they first appear. Messages that do not name a peer are ignored.
+func FailedPeers(messages []SplunkMessage) []string {
+	var peers []string
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		for _, m := range peerNamePattern.FindAllStringSubmatch(msg.Text, -1) {
+			peer := strings.TrimRight(m[1], ".:-")
+			if peer == "" || seen[peer] {
+				continue
+			}
+			seen[peer] = true
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
 // JobStatus retrieves the current status of a search job.
 func (c *Client) JobStatus(sid string) (bool, string, []SplunkMessage, int, error) {
-	endpoint, err := c.createAPIURL("search", "jobs", sid)
+	return c.jobStatus(context.Background(), sid)
+}
+
+func (c *Client) jobStatus(ctx context.Context, sid string) (bool, string, []SplunkMessage, int, error) {
+	content, err := c.fetchJobContent(ctx, sid)
 	if err != nil {
 		return false, "", nil, 0, err
 	}
+	return content.IsDone, content.DispatchState, content.Messages, content.ResultCount, nil
+}
+
+// jobContent holds the fields of a job entry that the client relies on.
+type jobContent struct {
+	IsDone        bool            `json:"isDone"`
+	DispatchState string          `json:"dispatchState"`
+	Messages      []SplunkMessage `json:"messages"`
+	ResultCount   int             `json:"resultCount"`
+	EventCount    int             `json:"eventCount"`
+	EarliestTime  string          `json:"earliestTime"`
+	LatestTime    string          `json:"latestTime"`
+}
+
+// fetchJobContent fetches a job's entry from search/jobs/{sid}.
+func (c *Client) fetchJobContent(ctx context.Context, sid string) (*jobContent, error) {
+	endpoint, err := c.createAPIURL("search", "jobs", sid)
+	if err != nil {
+		return nil, err
+	}
 	c.Log.Debugf(`Request: GET %s
 `, endpoint)
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
-		return false, "", nil, 0, err
+		return nil, err
 	}
 
 	q := req.URL.Query()
@@ -220,160 +793,257 @@ func (c *Client) JobStatus(sid string) (bool, string, []SplunkMessage, int, erro
 
 	resp, err := c.doRequest(req)
 	if err != nil {
-		return false, "", nil, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
-		return false, "", nil, 0, err
+		return nil, jobError(sid, err)
 	}
 
 	var status struct {
 		Entry []struct {
-			Content struct {
-				IsDone        bool            `json:"isDone"`
-				DispatchState string          `json:"dispatchState"`
-				Messages      []SplunkMessage `json:"messages"`
-				ResultCount   int             `json:"resultCount"`
-			} `json:"content"`
+			Content jobContent `json:"content"`
 		} `json:"entry"`
 	}
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return false, "", nil, 0, fmt.Errorf(`failed to read job status response body: %w`, err)
+		return nil, fmt.Errorf(`failed to read job status response body: %w`, err)
 	}
 
 	if err := json.Unmarshal(bodyBytes, &status); err != nil {
-		return false, "", nil, 0, fmt.Errorf(`failed to decode job status JSON: %w. Received: %s`, err, string(bodyBytes))
+		return nil, fmt.Errorf(`failed to decode job status JSON: %w. Received: %s`, err, string(bodyBytes))
 	}
 
 	if len(status.Entry) == 0 {
-		return false, "", nil, 0, errors.New("job status not found in response")
+		return nil, ErrJobStatusNotFound
 	}
-	content := status.Entry[0].Content
-	return content.IsDone, content.DispatchState, content.Messages, content.ResultCount, nil
+	content := &status.Entry[0].Content
+	if content.IsDone {
+		c.rememberDoneJob(sid, content)
+	}
+	return content, nil
 }
 
+// doneJobTTL is how long the status of a finished job is reused.
+const doneJobTTL = time.Second
 
-// WaitForJob waits for a job to finish, with a timeout.
-func (c *Client) WaitForJob(ctx context.Context, sid string) error {
-	c.Log.Println("Waiting for job to complete...")
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			done, jobState, messages, _, err := c.JobStatus(sid)
-			if err != nil {
-				return err
-			}
+type cachedJobContent struct {
+	content *jobContent
+	fetched time.Time
+}
 
-			if done {
-				if jobState == "FAILED" {
-					var errorMessages strings.Builder
-					for _, msg := range messages {
-						if strings.ToUpper(msg.Type) == "FATAL" || strings.ToUpper(msg.Type) == "ERROR" {
-							errorMessages.WriteString(fmt.Sprintf(`
-  - %s`, msg.Text))
-						}
-					}
-					if errorMessages.Len() > 0 {
-						return fmt.Errorf(`search job %s failed with errors:%s`, sid, errorMessages.String())
-					}
-					return fmt.Errorf(`search job %s failed`, sid)
-				}
-				c.Log.Println("Job finished.")
-				return nil
-			}
+// rememberDoneJob caches the status of a finished job, whose counts can no
+// longer change, so that fetching its results right after the final poll of
+// WaitForJob does not request the same status again.
+func (c *Client) rememberDoneJob(sid string, content *jobContent) {
+	c.doneJobsMu.Lock()
+	defer c.doneJobsMu.Unlock()
+	now := time.Now()
+	if c.doneJobs == nil {
+		c.doneJobs = map[string]cachedJobContent{}
+	}
+	for k, v := range c.doneJobs {
+		if now.Sub(v.fetched) > doneJobTTL {
+			delete(c.doneJobs, k)
 		}
 	}
+	c.doneJobs[sid] = cachedJobContent{content: content, fetched: now}
 }
 
-// Results fetches the results of a completed search job, handling pagination.
-func (c *Client) Results(sid string, limit int) (string, error) {
-	// 1. Get the total number of results for the job
-	_, _, _, totalResults, err := c.JobStatus(sid)
-	if err != nil {
-		return "", fmt.Errorf("could not get job status before fetching results: %w", err)
+// doneJobContent returns the status of a finished job seen within doneJobTTL,
+// fetching it otherwise.
+func (c *Client) doneJobContent(ctx context.Context, sid string) (*jobContent, error) {
+	c.doneJobsMu.Lock()
+	cached, ok := c.doneJobs[sid]
+	c.doneJobsMu.Unlock()
+	if ok && time.Since(cached.fetched) <= doneJobTTL {
+		c.Log.Debugf("Reusing status of finished job %s fetched %s ago\n", sid, time.Since(cached.fetched).Round(time.Millisecond))
+		return cached.content, nil
 	}
+	return c.fetchJobContent(ctx, sid)
+}
 
-	// 2. Determine the number of results to fetch
-	fetchCount := limit
-	if limit == 0 || (limit > 0 && limit > totalResults) {
-		fetchCount = totalResults
-	}
 
-	// 3. Fetch results, with pagination if necessary
-	const maxCount = 50000 // Max results per request
-	var allResults []json.RawMessage
+const (
+	pollInterval      = 2 * time.Second
+	maxQueuedInterval = 10 * time.Second
+)
 
-	for offset := 0; offset < fetchCount; offset += maxCount {
-		// Determine count for this specific request
-		count := maxCount
-		if offset+count > fetchCount {
-			count = fetchCount - offset
-		}
+// jittered returns d shifted randomly by up to the configured PollJitter
+// fraction in either direction, so that clients started together do not
+// keep polling the search head in lockstep.
+func (c *Client) jittered(d time.Duration) time.Duration {
+	if c.cfg.PollJitter == 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + c.cfg.PollJitter*(2*mathrand.Float64()-1)))
+}
 
-		// Prepare request
-		endpoint, err := c.createAPIURL("search", "jobs", sid, "results")
-		if err != nil {
-			return "", err
-		}
-		c.Log.Debugf(`Request: GET %s (offset: %d, count: %d)
-`, endpoint, offset, count)
+// windowPollInterval is how often SearchWindow checks whether the job's time
+// range has been resolved.
+const windowPollInterval = 250 * time.Millisecond
 
-		req, err := http.NewRequest("GET", endpoint, nil)
-		if err != nil {
-			return "", err
-		}
-		q := req.URL.Query()
-		q.Add("output_mode", "json")
-		q.Add("offset", fmt.Sprintf("%d", offset))
-		q.Add("count", fmt.Sprintf("%d", count))
-		req.URL.RawQuery = q.Encode()
-
-		// Execute request
-		resp, err := c.doRequest(req)
-		if err != nil {
-			return "", err
+// SearchWindow returns the absolute earliest and latest times (ISO 8601) that
+// Splunk resolved for a job's time range. The range is only known once the job
+// has been parsed, so it polls until then or until ctx is done. An empty value
+// means the bound is open (e.g. all time) or could not be resolved in time.
+func (c *Client) SearchWindow(ctx context.Context, sid string) (earliest, latest string, err error) {
+	for {
+		content, err := c.fetchJobContent(ctx, sid)
+		if err != nil && !errors.Is(err, ErrJobStatusNotFound) {
+			return "", "", err
 		}
-		defer resp.Body.Close()
-
-		if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
-			return "", err
+		if err == nil && (!isEarlyDispatchState(content.DispatchState) || content.IsDone) {
+			return content.EarliestTime, content.LatestTime, nil
 		}
 
-		// Decode and append results
-		var page struct {
-			Results []json.RawMessage `json:"results"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
-			return "", fmt.Errorf("failed to decode results page: %w", err)
+		select {
+		case <-ctx.Done():
+			if content != nil {
+				return content.EarliestTime, content.LatestTime, nil
+			}
+			return "", "", ctx.Err()
+		case <-time.After(windowPollInterval):
 		}
-		allResults = append(allResults, page.Results...)
 	}
+}
 
-	// 4. Combine and format the final JSON output
-	finalJSON := map[string][]json.RawMessage{
-		"results": allResults,
-	}
+// isEarlyDispatchState reports whether a job is still waiting to run, during
+// which polling can be less aggressive.
+func isEarlyDispatchState(state string) bool {
+	return state == "QUEUED" || state == "PARSING"
+}
 
-	prettyJSON, err := json.MarshalIndent(finalJSON, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal final results: %w", err)
+// JobStatus is the status of a search job as reported by search/jobs/{sid}.
+type JobStatus struct {
+	IsDone        bool
+	DispatchState string
+	Messages      []SplunkMessage
+	ResultCount   int
+	EventCount    int
+}
+
+// JobFailedError is returned by WaitForJobStatus when the job ended in the
+// FAILED dispatch state on the server, as opposed to a request failing.
+type JobFailedError struct {
+	SID string
+	// Messages are the job's ERROR and FATAL messages.
+	Messages []SplunkMessage
+}
+
+func (e *JobFailedError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("search job %s failed", e.SID)
 	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "search job %s failed with errors:", e.SID)
+	for _, msg := range e.Messages {
+		fmt.Fprintf(&b, "\n  - %s", msg.Text)
+	}
+	return b.String()
+}
 
-	return string(prettyJSON), nil
+// WaitForJob waits for a job to finish, with a timeout. It is WaitForJobStatus
+// for callers that do not need the final status.
+func (c *Client) WaitForJob(ctx context.Context, sid string) error {
+	_, err := c.WaitForJobStatus(ctx, sid)
+	return err
 }
 
+// WaitForJobStatus waits for a job to finish, with a timeout, and returns its
+// final status. A job that ended in the FAILED state returns its final status
+// together with a *JobFailedError. While the job is QUEUED or PARSING, the poll interval backs off
+// exponentially up to 10s; once the job is running it returns to the regular
+// 2s interval.
+func (c *Client) WaitForJobStatus(ctx context.Context, sid string) (*JobStatus, error) {
+	c.Log.Println("Waiting for job to complete...")
+	interval := pollInterval
+	timer := time.NewTimer(c.jittered(interval))
+	defer timer.Stop()
+
+	seen := false
+	emptyPolls := 0
+	lastState := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			content, err := c.fetchJobContent(ctx, sid)
+			if errors.Is(err, ErrJobStatusNotFound) && !seen && emptyPolls < jobStatusGracePolls {
+				// A freshly created job may not be visible yet; keep polling.
+				emptyPolls++
+				c.Log.Debugf("Job %s not visible yet (empty status response %d of %d)\n", sid, emptyPolls, jobStatusGracePolls)
+				timer.Reset(c.jittered(interval))
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			seen = true
+
+			jobState := content.DispatchState
+			if jobState != lastState {
+				c.Log.Debugf("Job %s dispatch state: %s -> %s\n", sid, lastState, jobState)
+				lastState = jobState
+			}
+
+			if content.IsDone {
+				status := &JobStatus{
+					IsDone:        content.IsDone,
+					DispatchState: content.DispatchState,
+					Messages:      content.Messages,
+					ResultCount:   content.ResultCount,
+					EventCount:    content.EventCount,
+				}
+				if jobState == "FAILED" {
+					var errs []SplunkMessage
+					for _, msg := range content.Messages {
+						if strings.ToUpper(msg.Type) == "FATAL" || strings.ToUpper(msg.Type) == "ERROR" {
+							errs = append(errs, msg)
+						}
+					}
+					return status, &JobFailedError{SID: sid, Messages: errs}
+				}
+				c.Log.Println("Job finished.")
+				return status, nil
+			}
+
+			if isEarlyDispatchState(jobState) {
+				interval = min(interval*2, maxQueuedInterval)
+			} else {
+				interval = pollInterval
+			}
+			timer.Reset(c.jittered(interval))
+		}
+	}
+}
 
 // CancelSearch sends a request to cancel a running job.
 func (c *Client) CancelSearch(sid string) error {
+	return c.CancelSearchContext(context.Background(), sid)
+}
+
+// CancelSearchContext is like CancelSearch, with the request bound to ctx.
+func (c *Client) CancelSearchContext(ctx context.Context, sid string) error {
 	c.Log.Println(`
 Cancelling search job...`)
+	if err := c.controlJob(ctx, sid, "cancel"); err != nil {
+		return err
+	}
+	c.Log.Println("Job successfully cancelled.")
+	return nil
+}
+
+// FinalizeSearch stops a running job early. Unlike cancelling, the results
+// found so far are kept, and the job becomes done shortly afterwards.
+func (c *Client) FinalizeSearch(ctx context.Context, sid string) error {
+	return c.controlJob(ctx, sid, "finalize")
+}
+
+// controlJob sends a control action such as cancel or finalize to job sid.
+func (c *Client) controlJob(ctx context.Context, sid, action string) error {
 	endpoint, err := c.createAPIURL("search", "jobs", sid, "control")
 	if err != nil {
 		return err
@@ -381,7 +1051,7 @@ Cancelling search job...`)
 	c.Log.Debugf(`Request: POST %s
 `, endpoint)
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader("action=cancel"))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader("action="+action))
 	if err != nil {
 		return err
 	}
@@ -393,10 +1063,10 @@ Cancelling search job...`)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		c.Log.Println("Job successfully cancelled.")
+	// Control actions may be acknowledged with any 2xx status depending on the
+	// Splunk version or a proxy in front of it.
+	if isSuccess(resp.StatusCode) {
 		return nil
 	}
-	body, _ := io.ReadAll(resp.Body)
-	return fmt.Errorf(`failed to cancel job: %s, %s`, resp.Status, string(body))
+	return fmt.Errorf("failed to %s job: %w", action, jobError(sid, newAPIError(resp)))
 }
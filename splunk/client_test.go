@@ -0,0 +1,179 @@
+package splunk
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// secretsOfLengths returns a token-like and a session-key-like secret of each
+// length the masking rules distinguish.
+func secretsOfLengths() map[string]string {
+	secrets := map[string]string{}
+	for _, n := range []int{1, 4, 8, 12, 40} {
+		secrets["token/"+strings.Repeat("x", n)] = strings.Repeat("t", n-1) + "Z"
+		secrets["session key/"+strings.Repeat("x", n)] = strings.Repeat("k", n-1) + "Q"
+	}
+	return secrets
+}
+
+func TestMaskSecret(t *testing.T) {
+	for name, secret := range secretsOfLengths() {
+		t.Run(name, func(t *testing.T) {
+			masked := MaskSecret(secret)
+			if strings.Contains(masked, secret) {
+				t.Fatalf("MaskSecret(%q) = %q contains the secret", secret, masked)
+			}
+			want := "****"
+			if len(secret) >= minMaskedSecretLen {
+				want += secret[len(secret)-4:]
+			}
+			if masked != want {
+				t.Errorf("MaskSecret(%q) = %q, want %q", secret, masked, want)
+			}
+		})
+	}
+	if got := MaskSecret(""); got != "" {
+		t.Errorf("MaskSecret(\"\") = %q, want \"\"", got)
+	}
+}
+
+func TestMaskHeaders(t *testing.T) {
+	for name, secret := range secretsOfLengths() {
+		t.Run(name, func(t *testing.T) {
+			for _, scheme := range []string{"Bearer", "Splunk", "Basic"} {
+				dump := "GET /services HTTP/1.1\r\nAuthorization: " + scheme + " " + secret + "\r\nX-Other: " + secret + "\r\n\r\nbody"
+				got := maskHeaders(dump, []string{"X-Other"})
+				if strings.Contains(got, secret) {
+					t.Errorf("%s: masked dump still contains the secret:\n%s", scheme, got)
+				}
+				if !strings.Contains(got, "Authorization: "+scheme+" "+MaskSecret(secret)+"\r\n") {
+					t.Errorf("%s: Authorization header not masked as expected:\n%s", scheme, got)
+				}
+			}
+		})
+	}
+}
+
+// TestDebugDumpShortToken checks that a short token is masked in the
+// Authorization header without rewriting matching text in the request body.
+func TestDebugDumpShortToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		token    string
+		maskBody bool
+	}{
+		{"ab", false},
+		{"abcdefgh", false},
+		{"abcdefghijkl", true},
+		{strings.Repeat("abcd", 10), true},
+	} {
+		t.Run(tc.token, func(t *testing.T) {
+			client, err := NewClient(&Config{Host: srv.URL, Token: tc.token, Debug: true}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var log bytes.Buffer
+			client.Log.Out = &log
+
+			body := "search=search+" + tc.token + "+tab"
+			req, err := http.NewRequest("POST", srv.URL+"/services/search/jobs", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.doRequest(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			dump := log.String()
+			if !strings.Contains(dump, "Authorization: Bearer "+MaskSecret(tc.token)+"\r\n") {
+				t.Errorf("Authorization header not masked:\n%s", dump)
+			}
+			if strings.Contains(dump, "Bearer "+tc.token) {
+				t.Errorf("full token in Authorization header:\n%s", dump)
+			}
+			if tc.maskBody {
+				if strings.Contains(dump, tc.token) {
+					t.Errorf("long token not masked in the body:\n%s", dump)
+				}
+			} else if !strings.Contains(dump, body) {
+				t.Errorf("short token masking rewrote the body %q:\n%s", body, dump)
+			}
+		})
+	}
+}
+
+func TestDebugDumpMaxFieldBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Long", strings.Repeat("h", 500))
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz", Debug: true, DebugMaxFieldBytes: 64}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log bytes.Buffer
+	client.Log.Out = &log
+
+	body := "search=" + strings.Repeat("x", 10000)
+	req, err := http.NewRequest("POST", srv.URL+"/services/search/jobs", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.doRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	client.handleFailedResponse(resp, http.StatusOK)
+
+	dump := log.String()
+	if want := body[:64] + "... [10007 bytes]"; !strings.Contains(dump, want) {
+		t.Errorf("request body not shortened to %q:\n%s", want, dump)
+	}
+	if want := "X-Long: " + strings.Repeat("h", 64) + "... [500 bytes]"; !strings.Contains(dump, want) {
+		t.Errorf("response header not shortened to %q:\n%s", want, dump)
+	}
+	if !strings.Contains(dump, "Authorization: Bearer ****\r\n") {
+		t.Errorf("short lines were changed:\n%s", dump)
+	}
+}
+
+func TestTruncateField(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"abcdef", 3, "abc... [6 bytes]"},
+		{"héllo", 2, "h... [6 bytes]"}, // never splits the two bytes of é
+		{"abc", 0, "... [3 bytes]"},
+	} {
+		if got := TruncateField(tc.in, tc.max); got != tc.want {
+			t.Errorf("TruncateField(%q, %d) = %q, want %q", tc.in, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestFailedPeers(t *testing.T) {
+	messages := []SplunkMessage{
+		{Type: "WARN", Text: "Search results might be incomplete: the search process on peer idx1 ended prematurely."},
+		{Type: "WARN", Text: "Unable to distribute to peer named idx2.example.com at uri=idx2.example.com:8089."},
+		{Type: "WARN", Text: "The search process on peer=idx1 ended prematurely."},
+		{Type: "WARN", Text: "Search results may be incomplete."},
+	}
+	got := FailedPeers(messages)
+	want := []string{"idx1", "idx2.example.com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FailedPeers = %q, want %q", got, want)
+	}
+}
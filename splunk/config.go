@@ -2,42 +2,101 @@ package splunk
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Config stores all configuration options.
 type Config struct {
-	Host        string        `json:"host"`
-	Token       string        `json:"token"`
-	User        string        `json:"user"`
-	Password    string        `json:"password"`
-	App         string        `json:"app"`
-	Owner       string        `json:"owner"`
-	Insecure    bool          `json:"insecure"`
-	HTTPTimeout time.Duration `json:"httpTimeout"`
-	Limit       int           `json:"limit"`
-	Debug       bool          `json:"-"` // Exclude from JSON marshalling
+	Host     string `json:"host"`
+	Token    string `json:"token"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	App      string `json:"app"`
+	Owner    string `json:"owner"`
+	Insecure bool   `json:"insecure"`
+	// SkipHostnameVerify validates the server's certificate chain but accepts
+	// a certificate issued for a different host name.
+	SkipHostnameVerify bool          `json:"skipHostnameVerify"`
+	HTTPTimeout        time.Duration `json:"httpTimeout"`
+	Limit              int           `json:"limit"`
+	Retries            int           `json:"retries"`
+	UserAgent          string        `json:"userAgent"`
+	// Connection pool limits; 0 selects the transport defaults chosen in NewClient.
+	MaxIdleConns    int `json:"maxIdleConns"`
+	MaxConnsPerHost int `json:"maxConnsPerHost"`
+	// Search time range used by run and start when --earliest/--latest are not given.
+	DefaultEarliest string `json:"defaultEarliest"`
+	DefaultLatest   string `json:"defaultLatest"`
+	// Extra headers sent with every request, applied after authentication.
+	Headers map[string]string `json:"headers"`
+	// Header names, in addition to Authorization, whose values are masked in debug dumps.
+	SensitiveHeaders []string `json:"sensitiveHeaders"`
+	// Shell command whose output is used as the token when no token is set,
+	// e.g. to fetch a short-lived token from a secrets manager.
+	TokenCommand string  `json:"tokenCommand"`
+	PollJitter   float64 `json:"-"` // Fraction by which job status poll intervals are randomized
+	RequestID    string  `json:"-"` // Fixed X-Request-ID for every request; generated per request if empty
+	ConfigDir    string  `json:"-"` // Base directory for config and state files
+	Debug        bool    `json:"-"` // Exclude from JSON marshalling
+	// DebugMaxFieldBytes shortens lines of the debug log, such as a request
+	// body carrying a huge search, that are longer than this many bytes; 0
+	// for no limit.
+	DebugMaxFieldBytes int `json:"-"`
 }
 
-// LoadConfigFromFile loads configuration from the user's config directory.
-// It now accepts an optional customConfigPath. If provided, it uses that path.
-func LoadConfigFromFile(customConfigPath string) (Config, string, error) {
+// DefaultPollJitter is the default PollJitter: poll intervals vary by +/-10%.
+const DefaultPollJitter = 0.1
+
+// DefaultUserAgent returns the User-Agent sent when none is configured.
+func DefaultUserAgent(version string) string {
+	return "splunk-cli/" + version
+}
+
+// ResolveConfigDir determines the base directory for configuration and state files.
+// In order of precedence it uses customDir (--config-dir), SPLUNK_CONFIG_DIR,
+// $XDG_CONFIG_HOME/splunk-cli, and finally ~/.config/splunk-cli.
+func ResolveConfigDir(customDir string) (string, error) {
+	if customDir != "" {
+		return customDir, nil
+	}
+	if dir := os.Getenv("SPLUNK_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "splunk-cli"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "splunk-cli"), nil
+}
+
+// LoadConfigFromFile loads configuration from config.json in configDir.
+// If customConfigPath is provided, it uses that path instead. A missing
+// config.json yields an empty config, but a missing customConfigPath is an
+// error, since the user asked for that file explicitly.
+func LoadConfigFromFile(customConfigPath, configDir string) (Config, string, error) {
 	var cfg Config
 	configFile := customConfigPath // Use custom path if provided
 
-	if configFile == "" { // If no custom path, use default
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return cfg, "", fmt.Errorf("could not get user home directory: %w", err)
+	if configFile == "" { // If no custom path, use the config directory
+		if configDir == "" {
+			return cfg, "", fmt.Errorf("no config directory available")
 		}
-		configFile = filepath.Join(home, ".config", "splunk-cli", "config.json")
+		configFile = filepath.Join(configDir, "config.json")
 	}
 
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		if customConfigPath != "" {
+			return cfg, configFile, errors.New("config file does not exist")
+		}
 		return cfg, configFile, nil
 	}
 
@@ -47,39 +106,145 @@ func LoadConfigFromFile(customConfigPath string) (Config, string, error) {
 	}
 	defer file.Close()
 
-	type configHelper struct {
-		Host        string `json:"host"`
-		Token       string `json:"token"`
-		User        string `json:"user"`
-		Password    string `json:"password"`
-		App         string `json:"app"`
-		Owner       string `json:"owner"`
-		Insecure    bool   `json:"insecure"`
-		HTTPTimeout string `json:"httpTimeout"`
-		Limit       int    `json:"limit"`
-	}
 	var helper configHelper
 	if err := json.NewDecoder(file).Decode(&helper); err != nil {
 		return cfg, configFile, fmt.Errorf("could not parse config file: %w", err)
 	}
+	cfg, err = helper.config()
+	return cfg, configFile, err
+}
+
+// MergeConfigJSON merges data, a JSON object in the config file format, over
+// cfg. Only the keys present in data change cfg; unknown keys are an error.
+func MergeConfigJSON(cfg *Config, data string) error {
+	helper := newConfigHelper(cfg)
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&helper); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("invalid JSON: unexpected data after the object")
+	}
+	merged, err := helper.config()
+	if err != nil {
+		return err
+	}
+	merged.RequestID, merged.ConfigDir, merged.Debug = cfg.RequestID, cfg.ConfigDir, cfg.Debug
+	*cfg = merged
+	return nil
+}
 
+// configHelper is the config file format. Durations are decoded separately so
+// they can be given as a string or a number of seconds.
+type configHelper struct {
+	Host               string            `json:"host"`
+	Token              string            `json:"token"`
+	TokenCommand       string            `json:"tokenCommand"`
+	User               string            `json:"user"`
+	Password           string            `json:"password"`
+	App                string            `json:"app"`
+	Owner              string            `json:"owner"`
+	Insecure           bool              `json:"insecure"`
+	SkipHostnameVerify bool              `json:"skipHostnameVerify"`
+	HTTPTimeout        json.RawMessage   `json:"httpTimeout"`
+	Limit              int               `json:"limit"`
+	Retries            int               `json:"retries"`
+	UserAgent          string            `json:"userAgent"`
+	MaxIdleConns       int               `json:"maxIdleConns"`
+	MaxConnsPerHost    int               `json:"maxConnsPerHost"`
+	DefaultEarliest    string            `json:"defaultEarliest"`
+	DefaultLatest      string            `json:"defaultLatest"`
+	Headers            map[string]string `json:"headers"`
+	SensitiveHeaders   []string          `json:"sensitiveHeaders"`
+}
+
+// newConfigHelper returns the config file form of cfg, so JSON can be decoded
+// over it.
+func newConfigHelper(cfg *Config) configHelper {
+	helper := configHelper{
+		Host:               cfg.Host,
+		Token:              cfg.Token,
+		TokenCommand:       cfg.TokenCommand,
+		User:               cfg.User,
+		Password:           cfg.Password,
+		App:                cfg.App,
+		Owner:              cfg.Owner,
+		Insecure:           cfg.Insecure,
+		SkipHostnameVerify: cfg.SkipHostnameVerify,
+		Limit:              cfg.Limit,
+		Retries:            cfg.Retries,
+		UserAgent:          cfg.UserAgent,
+		MaxIdleConns:       cfg.MaxIdleConns,
+		MaxConnsPerHost:    cfg.MaxConnsPerHost,
+		DefaultEarliest:    cfg.DefaultEarliest,
+		DefaultLatest:      cfg.DefaultLatest,
+		Headers:            cfg.Headers,
+		SensitiveHeaders:   cfg.SensitiveHeaders,
+	}
+	if cfg.HTTPTimeout != 0 {
+		helper.HTTPTimeout, _ = json.Marshal(cfg.HTTPTimeout.String())
+	}
+	return helper
+}
+
+// config converts the config file form into a Config.
+func (helper *configHelper) config() (Config, error) {
+	var cfg Config
+	var err error
 	cfg.Host = strings.TrimSpace(helper.Host)
 	cfg.Token = strings.TrimSpace(helper.Token)
+	cfg.TokenCommand = strings.TrimSpace(helper.TokenCommand)
 	cfg.User = strings.TrimSpace(helper.User)
 	cfg.Password = strings.TrimSpace(helper.Password)
 	cfg.App = strings.TrimSpace(helper.App)
 	cfg.Owner = strings.TrimSpace(helper.Owner)
 	cfg.Insecure = helper.Insecure
+	cfg.SkipHostnameVerify = helper.SkipHostnameVerify
 	cfg.Limit = helper.Limit
-	if helper.HTTPTimeout != "" {
-		parsedDuration, err := time.ParseDuration(helper.HTTPTimeout)
-		if err != nil {
-			return cfg, configFile, fmt.Errorf("invalid httpTimeout value in config: %w", err)
-		}
-		cfg.HTTPTimeout = parsedDuration
+	cfg.Retries = helper.Retries
+	cfg.UserAgent = strings.TrimSpace(helper.UserAgent)
+	cfg.MaxIdleConns = helper.MaxIdleConns
+	cfg.MaxConnsPerHost = helper.MaxConnsPerHost
+	cfg.DefaultEarliest = strings.TrimSpace(helper.DefaultEarliest)
+	cfg.DefaultLatest = strings.TrimSpace(helper.DefaultLatest)
+	cfg.Headers = helper.Headers
+	cfg.SensitiveHeaders = helper.SensitiveHeaders
+	if cfg.HTTPTimeout, err = parseConfigDuration(helper.HTTPTimeout); err != nil {
+		return cfg, fmt.Errorf("invalid httpTimeout value in config: %w", err)
+	}
+	return cfg, nil
+}
+
+// parseConfigDuration parses a duration value from the config file. It accepts a
+// Go duration string ("30s", "1m30s"), or a bare number interpreted as seconds
+// (30, 2.5). A missing or empty value yields zero.
+func parseConfigDuration(raw json.RawMessage) (time.Duration, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
 	}
 
-	return cfg, configFile, nil
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return 0, fmt.Errorf("expected a duration string or a number of seconds, got %s", string(raw))
+	}
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseFloat(str, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is neither a duration (e.g. '30s') nor a number of seconds", str)
+	}
+	return d, nil
 }
 
 // ProcessEnvVars overwrites config with values from environment variables.
@@ -99,4 +264,10 @@ func ProcessEnvVars(cfg *Config) {
 	if app := os.Getenv("SPLUNK_APP"); app != "" {
 		cfg.App = app
 	}
+	if earliest := os.Getenv("SPLUNK_EARLIEST"); earliest != "" {
+		cfg.DefaultEarliest = earliest
+	}
+	if latest := os.Getenv("SPLUNK_LATEST"); latest != "" {
+		cfg.DefaultLatest = latest
+	}
 }
@@ -0,0 +1,90 @@
+package splunk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Export runs spl through the export endpoint, which streams the results as
+// the search produces them instead of creating a job to poll, and calls emit
+// with each final result row in order. It returns the number of rows emitted.
+//
+// With output_mode=json Splunk does not send one document but a stream of
+// concatenated JSON objects, one per row, each wrapping the row in "result"
+// alongside "preview", "offset", and "lastrow" markers. Preview rows of
+// transforming searches are superseded by the final ones and are skipped, and
+// the stream ends after the row marked lastrow. Objects carrying "messages"
+// instead of a row report problems: ERROR and FATAL messages fail the export,
+// others are logged.
+//
+// The whole stream is one HTTP response, so it is bounded by the configured
+// HTTP timeout as well as by ctx.
+func (c *Client) Export(ctx context.Context, spl string, opts SearchOptions, emit func(row json.RawMessage) error) (int, error) {
+	endpoint, err := c.createAPIURL("search", "jobs", "export")
+	if err != nil {
+		return 0, err
+	}
+	c.Log.Debugf(`Request: POST %s
+`, endpoint)
+
+	form := searchForm(spl, opts)
+	form.Set("output_mode", "json")
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return 0, err
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	rows := 0
+	for {
+		var chunk struct {
+			Preview  bool            `json:"preview"`
+			LastRow  bool            `json:"lastrow"`
+			Result   json.RawMessage `json:"result"`
+			Messages []SplunkMessage `json:"messages"`
+		}
+		if err := dec.Decode(&chunk); errors.Is(err, io.EOF) {
+			return rows, nil
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return rows, ctx.Err()
+			}
+			return rows, fmt.Errorf("failed to decode export stream after %d rows: %w", rows, err)
+		}
+
+		for _, msg := range chunk.Messages {
+			switch strings.ToUpper(msg.Type) {
+			case "ERROR", "FATAL":
+				return rows, fmt.Errorf("export failed: %s", msg.Text)
+			default:
+				c.Log.Printf("Splunk %s: %s\n", msg.Type, msg.Text)
+			}
+		}
+		if chunk.Preview || chunk.Result == nil {
+			continue
+		}
+		if err := emit(chunk.Result); err != nil {
+			return rows, err
+		}
+		rows++
+		if chunk.LastRow {
+			return rows, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// JobInspection is the detailed view of a search job used for performance
+// tuning, as returned by JobInspect.
+type JobInspection struct {
+	SID           string     `json:"sid"`
+	Search        string     `json:"search"`
+	DispatchState string     `json:"dispatchState"`
+	IsDone        bool       `json:"isDone"`
+	RunDuration   float64    `json:"runDuration"`
+	EventCount    int        `json:"eventCount"`
+	ScanCount     int        `json:"scanCount"`
+	ResultCount   int        `json:"resultCount"`
+	DiskUsage     int64      `json:"diskUsage"`
+	SearchLogPath string     `json:"searchLogPath,omitempty"`
+	Phases        []JobPhase `json:"phases"`
+}
+
+// JobPhase is one entry of a job's performance breakdown, such as
+// "command.search" or "dispatch.fetch".
+type JobPhase struct {
+	Name         string  `json:"name"`
+	DurationSecs float64 `json:"durationSecs"`
+	Invocations  int     `json:"invocations"`
+	InputCount   int     `json:"inputCount"`
+	OutputCount  int     `json:"outputCount"`
+}
+
+// JobInspect fetches a job's entry with its full content, including the
+// performance breakdown. Phases are sorted by duration, longest first.
+func (c *Client) JobInspect(sid string) (*JobInspection, error) {
+	endpoint, err := c.createAPIURL("search", "jobs", sid)
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return nil, jobError(sid, err)
+	}
+
+	var page struct {
+		Entry []struct {
+			Name    string            `json:"name"`
+			Links   map[string]string `json:"links"`
+			Content struct {
+				SID           string  `json:"sid"`
+				DispatchState string  `json:"dispatchState"`
+				IsDone        bool    `json:"isDone"`
+				RunDuration   float64 `json:"runDuration"`
+				EventCount    int     `json:"eventCount"`
+				ScanCount     int     `json:"scanCount"`
+				ResultCount   int     `json:"resultCount"`
+				DiskUsage     int64   `json:"diskUsage"`
+				Performance   map[string]struct {
+					DurationSecs float64 `json:"duration_secs"`
+					Invocations  int     `json:"invocations"`
+					InputCount   int     `json:"input_count"`
+					OutputCount  int     `json:"output_count"`
+				} `json:"performance"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode job inspection response: %w", err)
+	}
+	if len(page.Entry) == 0 {
+		return nil, ErrJobStatusNotFound
+	}
+
+	e := page.Entry[0]
+	inspection := &JobInspection{
+		SID:           e.Content.SID,
+		Search:        e.Name,
+		DispatchState: e.Content.DispatchState,
+		IsDone:        e.Content.IsDone,
+		RunDuration:   e.Content.RunDuration,
+		EventCount:    e.Content.EventCount,
+		ScanCount:     e.Content.ScanCount,
+		ResultCount:   e.Content.ResultCount,
+		DiskUsage:     e.Content.DiskUsage,
+		SearchLogPath: e.Links["search.log"],
+		Phases:        make([]JobPhase, 0, len(e.Content.Performance)),
+	}
+	if inspection.SID == "" {
+		inspection.SID = sid
+	}
+	for name, p := range e.Content.Performance {
+		inspection.Phases = append(inspection.Phases, JobPhase{
+			Name:         name,
+			DurationSecs: p.DurationSecs,
+			Invocations:  p.Invocations,
+			InputCount:   p.InputCount,
+			OutputCount:  p.OutputCount,
+		})
+	}
+	sort.Slice(inspection.Phases, func(i, j int) bool {
+		a, b := inspection.Phases[i], inspection.Phases[j]
+		if a.DurationSecs != b.DurationSecs {
+			return a.DurationSecs > b.DurationSecs
+		}
+		return a.Name < b.Name
+	})
+	return inspection, nil
+}
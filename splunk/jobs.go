@@ -0,0 +1,90 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Job summarizes a search job as returned by ListJobs. Label is the
+// custom.label set at dispatch, or else Splunk's own label of the job (the
+// name of the saved search that started it).
+type Job struct {
+	SID           string            `json:"sid"`
+	Search        string            `json:"search"`
+	DispatchState string            `json:"dispatchState"`
+	IsDone        bool              `json:"isDone"`
+	ResultCount   int               `json:"resultCount"`
+	Label         string            `json:"label,omitempty"`
+	Custom        map[string]string `json:"custom,omitempty"`
+}
+
+// ListJobs retrieves the search jobs visible to the authenticated user.
+// A limit of 0 returns all jobs.
+func (c *Client) ListJobs(limit int) ([]Job, error) {
+	endpoint, err := c.createAPIURL("search", "jobs")
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	q.Add("count", fmt.Sprintf("%d", limit))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Entry []struct {
+			Name    string `json:"name"`
+			Content struct {
+				SID           string         `json:"sid"`
+				DispatchState string         `json:"dispatchState"`
+				IsDone        bool           `json:"isDone"`
+				ResultCount   int            `json:"resultCount"`
+				Label         string         `json:"label"`
+				Custom        map[string]any `json:"custom"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs response: %w", err)
+	}
+
+	jobs := make([]Job, 0, len(page.Entry))
+	for _, e := range page.Entry {
+		job := Job{
+			SID:           e.Content.SID,
+			Search:        e.Name,
+			DispatchState: e.Content.DispatchState,
+			IsDone:        e.Content.IsDone,
+			ResultCount:   e.Content.ResultCount,
+			Label:         e.Content.Label,
+		}
+		if len(e.Content.Custom) > 0 {
+			job.Custom = make(map[string]string, len(e.Content.Custom))
+			for k, v := range e.Content.Custom {
+				job.Custom[k] = fmt.Sprint(v)
+			}
+			if label := job.Custom["label"]; label != "" {
+				job.Label = label
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,71 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestControlJobStatus(t *testing.T) {
+	tests := []struct {
+		status     int
+		wantErr    bool
+		wantAPIErr int
+		notFound   bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusAccepted},
+		{status: http.StatusNoContent},
+		{status: http.StatusBadRequest, wantErr: true, wantAPIErr: http.StatusBadRequest},
+		{status: http.StatusForbidden, wantErr: true, wantAPIErr: http.StatusForbidden},
+		{status: http.StatusNotFound, wantErr: true, wantAPIErr: http.StatusNotFound, notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var gotAction string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotAction = string(body)
+				if !strings.HasSuffix(r.URL.Path, "/search/jobs/job.1/control") {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz"}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, action := range []string{"cancel", "finalize"} {
+				var err error
+				if action == "cancel" {
+					err = client.CancelSearchContext(context.Background(), "job.1")
+				} else {
+					err = client.FinalizeSearch(context.Background(), "job.1")
+				}
+				if gotAction != "action="+action {
+					t.Errorf("%s sent %q", action, gotAction)
+				}
+				if !tt.wantErr {
+					if err != nil {
+						t.Errorf("%s: unexpected error %v", action, err)
+					}
+					continue
+				}
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantAPIErr {
+					t.Errorf("%s: err = %v, want an *APIError with status %d", action, err, tt.wantAPIErr)
+				}
+				var notFound *JobNotFoundError
+				if errors.As(err, &notFound) != tt.notFound {
+					t.Errorf("%s: JobNotFoundError = %t, want %t", action, !tt.notFound, tt.notFound)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Macro describes a search macro defined in macros.conf.
+type Macro struct {
+	Name       string `json:"name"`
+	App        string `json:"app"`
+	Args       string `json:"args"`
+	Definition string `json:"definition"`
+}
+
+// ListMacros retrieves search macros visible in the configured namespace.
+// When an app is configured, only macros defined in that app are returned.
+// A limit of 0 returns all macros; the limit applies after the app filter, so
+// macros of other apps visible in the namespace never take up its places.
+func (c *Client) ListMacros(limit int) ([]Macro, error) {
+	endpoint, err := c.createAPIURL("configs", "conf-macros")
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	if c.cfg.App != "" {
+		// Filter on the server, but fetch everything: the app filter below
+		// must run before the limit is applied.
+		q.Add("search", fmt.Sprintf("eai:acl.app=%q", c.cfg.App))
+		q.Add("count", "0")
+	} else {
+		q.Add("count", fmt.Sprintf("%d", limit))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Entry []struct {
+			Name string `json:"name"`
+			ACL  struct {
+				App string `json:"app"`
+			} `json:"acl"`
+			Content struct {
+				Args       string `json:"args"`
+				Definition string `json:"definition"`
+			} `json:"content"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode macros response: %w", err)
+	}
+
+	var macros []Macro
+	for _, e := range page.Entry {
+		if c.cfg.App != "" && e.ACL.App != c.cfg.App {
+			continue
+		}
+		macros = append(macros, Macro{
+			Name:       e.Name,
+			App:        e.ACL.App,
+			Args:       e.Content.Args,
+			Definition: e.Content.Definition,
+		})
+		if limit > 0 && len(macros) == limit {
+			break
+		}
+	}
+	return macros, nil
+}
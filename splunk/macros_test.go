@@ -0,0 +1,74 @@
+package splunk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// TestListMacrosLimitAfterAppFilter checks that --limit counts only the macros
+// of the configured app, even when other apps' macros come first.
+func TestListMacrosLimitAfterAppFilter(t *testing.T) {
+	type entry struct {
+		Name string            `json:"name"`
+		ACL  map[string]string `json:"acl"`
+	}
+	var entries []entry
+	for i := range 5 {
+		entries = append(entries, entry{"other" + strconv.Itoa(i), map[string]string{"app": "search"}})
+	}
+	for i := range 3 {
+		entries = append(entries, entry{"mine" + strconv.Itoa(i), map[string]string{"app": "ops"}})
+	}
+
+	tests := []struct {
+		app       string
+		limit     int
+		wantCount string
+		wantNames []string
+	}{
+		{app: "ops", limit: 2, wantCount: "0", wantNames: []string{"mine0", "mine1"}},
+		{app: "ops", limit: 0, wantCount: "0", wantNames: []string{"mine0", "mine1", "mine2"}},
+		{app: "", limit: 2, wantCount: "2", wantNames: []string{"other0", "other1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.app+"/"+strconv.Itoa(tt.limit), func(t *testing.T) {
+			var query map[string][]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				// Like a server that ignores the search filter, honor only count.
+				served := entries
+				if n, _ := strconv.Atoi(r.URL.Query().Get("count")); n > 0 && n < len(served) {
+					served = served[:n]
+				}
+				json.NewEncoder(w).Encode(map[string]any{"entry": served})
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz", App: tt.app}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			macros, err := client.ListMacros(tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, m := range macros {
+				names = append(names, m.Name)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("macros = %v, want %v", names, tt.wantNames)
+			}
+			if got := query["count"]; len(got) != 1 || got[0] != tt.wantCount {
+				t.Errorf("count = %v, want %s", got, tt.wantCount)
+			}
+			if tt.app != "" && (len(query["search"]) != 1 || query["search"][0] != `eai:acl.app="ops"`) {
+				t.Errorf("search = %v, want the app filter", query["search"])
+			}
+		})
+	}
+}
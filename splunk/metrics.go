@@ -0,0 +1,161 @@
+package splunk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ServerMetrics is a snapshot of the load on the Splunk server the client is
+// connected to, e.g. to check a search head before dispatching heavy searches.
+type ServerMetrics struct {
+	CPUCount     int     `json:"cpuCount"`
+	CPUUserPct   float64 `json:"cpuUserPct"`
+	CPUSystemPct float64 `json:"cpuSystemPct"`
+	CPUIdlePct   float64 `json:"cpuIdlePct"`
+	// LoadAvg1Min is the one-minute load average divided by the number of
+	// CPUs, so 1.0 means fully loaded regardless of the machine size.
+	LoadAvg1Min float64 `json:"loadAvg1Min"`
+	MemTotalMB  float64 `json:"memTotalMB"`
+	MemUsedMB   float64 `json:"memUsedMB"`
+	// RunningSearches is the number of search processes on the server.
+	RunningSearches int `json:"runningSearches"`
+	// Search concurrency limits; 0 when the server does not report them.
+	MaxHistoricalSearches int `json:"maxHistoricalSearches,omitempty"`
+	MaxRealtimeSearches   int `json:"maxRealtimeSearches,omitempty"`
+}
+
+// metricNumber decodes a number that Splunk reports either as a JSON number
+// or as a string, depending on the endpoint and version.
+type metricNumber float64
+
+func (n *metricNumber) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "" {
+			*n = 0
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		*n = metricNumber(f)
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*n = metricNumber(f)
+	return nil
+}
+
+// ServerMetrics reads CPU, memory, and search concurrency figures from the
+// server's status endpoints, which are read-only and not namespaced. The
+// concurrency limits are left at 0 on servers that do not have their endpoint.
+func (c *Client) ServerMetrics() (*ServerMetrics, error) {
+	var hostwide []struct {
+		Content struct {
+			CPUCount     metricNumber `json:"cpu_count"`
+			CPUUserPct   metricNumber `json:"cpu_user_pct"`
+			CPUSystemPct metricNumber `json:"cpu_system_pct"`
+			CPUIdlePct   metricNumber `json:"cpu_idle_pct"`
+			LoadAvg1Min  metricNumber `json:"normalized_load_avg_1min"`
+			Mem          metricNumber `json:"mem"`
+			MemUsed      metricNumber `json:"mem_used"`
+		} `json:"content"`
+	}
+	if err := c.getStatusEntries(&hostwide, "server", "status", "resource-usage", "hostwide"); err != nil {
+		return nil, err
+	}
+	if len(hostwide) == 0 {
+		return nil, errors.New("resource usage not found in response")
+	}
+	h := hostwide[0].Content
+	metrics := &ServerMetrics{
+		CPUCount:     int(h.CPUCount),
+		CPUUserPct:   float64(h.CPUUserPct),
+		CPUSystemPct: float64(h.CPUSystemPct),
+		CPUIdlePct:   float64(h.CPUIdlePct),
+		LoadAvg1Min:  float64(h.LoadAvg1Min),
+		MemTotalMB:   float64(h.Mem),
+		MemUsedMB:    float64(h.MemUsed),
+	}
+
+	var processes []struct {
+		Content struct {
+			SearchProps json.RawMessage `json:"search_props"`
+		} `json:"content"`
+	}
+	if err := c.getStatusEntries(&processes, "server", "status", "resource-usage", "splunk-processes"); err != nil {
+		return nil, err
+	}
+	for _, p := range processes {
+		if len(p.Content.SearchProps) > 0 && string(p.Content.SearchProps) != "null" {
+			metrics.RunningSearches++
+		}
+	}
+
+	var limits []struct {
+		Content struct {
+			MaxHistorical metricNumber `json:"max_hist_searches"`
+			MaxRealtime   metricNumber `json:"max_rt_searches"`
+		} `json:"content"`
+	}
+	err := c.getStatusEntries(&limits, "server", "status", "limits", "search-concurrency")
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		c.Log.Debugf("Search concurrency limits are not available on this server\n")
+		return metrics, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(limits) > 0 {
+		metrics.MaxHistoricalSearches = int(limits[0].Content.MaxHistorical)
+		metrics.MaxRealtimeSearches = int(limits[0].Content.MaxRealtime)
+	}
+	return metrics, nil
+}
+
+// getStatusEntries fetches a non-namespaced status endpoint and decodes the
+// entries of its response into entries, a pointer to a slice of structs with
+// a Content field.
+func (c *Client) getStatusEntries(entries any, pathSegments ...string) error {
+	endpoint, err := c.createServicesURL(pathSegments...)
+	if err != nil {
+		return err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	q.Add("count", "0")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return err
+	}
+
+	page := struct {
+		Entry any `json:"entry"`
+	}{Entry: entries}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", pathSegments[len(pathSegments)-1], err)
+	}
+	return nil
+}
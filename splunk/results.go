@@ -0,0 +1,386 @@
+package splunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// maxResultsPerPage is the maximum number of results Splunk returns per request.
+const maxResultsPerPage = 50000
+
+// ResultsOptions selects the rows fetched by Results and ResultsCSV.
+type ResultsOptions struct {
+	Offset int // Index of the first row
+	Limit  int // Maximum number of rows; 0 for all
+	// PostProcess is SPL starting with '|' that Splunk applies to the rows
+	// before returning them.
+	PostProcess string
+	// Events fetches the job's events (search/jobs/{sid}/events) instead of
+	// its results.
+	Events bool
+	// Segmentation controls how _raw is marked up in events ("none", "raw",
+	// "full", ...). It is only sent with Events; empty leaves Splunk's default.
+	Segmentation string
+	// FirstColumn, when present in the results, is moved to the front of the
+	// columns written by ResultsCSV; the other columns keep their order.
+	FirstColumn string
+}
+
+// forEachResultsPage fetches the results (or events) of a job page by page in
+// the given output mode, calling fn with each page's response body. It fetches
+// up to opts.Limit rows starting at opts.Offset, never past the job's total
+// result (or event) count. A window with a non-zero offset must lie within the
+// total; with offset 0 the limit is clamped to it. With opts.PostProcess set,
+// see fetchPostProcessedResults.
+func (c *Client) forEachResultsPage(ctx context.Context, sid string, opts ResultsOptions, outputMode string, fn func(body io.Reader) error) error {
+	offset, limit := opts.Offset, opts.Limit
+	if offset < 0 {
+		return fmt.Errorf("offset must be >= 0, got %d", offset)
+	}
+	if opts.PostProcess != "" {
+		return c.fetchPostProcessedResults(ctx, sid, opts, outputMode, fn)
+	}
+
+	// 1. Get the total number of rows for the job, reusing the status that
+	// WaitForJob has usually just fetched
+	content, err := c.doneJobContent(ctx, sid)
+	if err != nil {
+		return fmt.Errorf("could not get job status before fetching results: %w", err)
+	}
+	total, kind := content.ResultCount, "results"
+	if opts.Events {
+		total, kind = content.EventCount, "events"
+	}
+	if offset > total {
+		return fmt.Errorf("offset %d is beyond the %d %s available for job %s", offset, total, kind, sid)
+	}
+	if offset > 0 && limit > 0 && offset+limit > total {
+		return fmt.Errorf("window of %d %s at offset %d extends beyond the %d %s available for job %s", limit, kind, offset, total, kind, sid)
+	}
+
+	// 2. Determine the number of rows to fetch. Without an offset, the limit is
+	// only a cap and a job with fewer rows returns all of them.
+	fetchCount := limit
+	if limit == 0 || limit > total-offset {
+		fetchCount = total - offset
+	}
+
+	// 3. Fetch rows, with pagination if necessary
+	for fetched := 0; fetched < fetchCount; fetched += maxResultsPerPage {
+		// Determine offset and count for this specific request
+		pageOffset := offset + fetched
+		count := maxResultsPerPage
+		if fetched+count > fetchCount {
+			count = fetchCount - fetched
+		}
+		if err := c.fetchResultsPage(ctx, sid, pageOffset, count, opts, outputMode, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchPostProcessedResults fetches post-processed results in a single request.
+// The job's result count says nothing about how many rows the post-process
+// search produces, so the window cannot be paginated up front; at most
+// maxResultsPerPage rows are returned.
+func (c *Client) fetchPostProcessedResults(ctx context.Context, sid string, opts ResultsOptions, outputMode string, fn func(body io.Reader) error) error {
+	count := opts.Limit
+	if count <= 0 || count > maxResultsPerPage {
+		count = maxResultsPerPage
+	}
+	return c.fetchResultsPage(ctx, sid, opts.Offset, count, opts, outputMode, fn)
+}
+
+func (c *Client) fetchResultsPage(ctx context.Context, sid string, offset, count int, opts ResultsOptions, outputMode string, fn func(body io.Reader) error) error {
+	source := "results"
+	if opts.Events {
+		source = "events"
+	}
+	endpoint, err := c.createAPIURL("search", "jobs", sid, source)
+	if err != nil {
+		return err
+	}
+	c.Log.Debugf(`Request: GET %s (offset: %d, count: %d)
+`, endpoint, offset, count)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", outputMode)
+	q.Add("offset", fmt.Sprintf("%d", offset))
+	q.Add("count", fmt.Sprintf("%d", count))
+	if opts.PostProcess != "" {
+		q.Add("search", opts.PostProcess)
+	}
+	if opts.Events && opts.Segmentation != "" {
+		q.Add("segmentation", opts.Segmentation)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return jobError(sid, err)
+	}
+	return fn(resp.Body)
+}
+
+// ResultsURL returns the REST URL from which the JSON results of job sid can
+// be fetched, in the client's app namespace. Credentials embedded in the host
+// URL are removed, so the URL can be handed to other programs.
+func (c *Client) ResultsURL(sid string) (string, error) {
+	endpoint, err := c.createAPIURL("search", "jobs", sid, "results")
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	u.User = nil
+	u.RawQuery = url.Values{"output_mode": {"json"}}.Encode()
+	return u.String(), nil
+}
+
+// Results fetches the results of a completed search job, handling pagination.
+// opts selects the window of rows and whether events are fetched instead.
+// The context bounds the whole fetch, across all pages. All rows are held in
+// memory; ForEachResult hands them over one at a time instead.
+func (c *Client) Results(ctx context.Context, sid string, opts ResultsOptions) ([]json.RawMessage, error) {
+	allResults := []json.RawMessage{}
+	err := c.ForEachResult(ctx, sid, opts, func(row json.RawMessage) error {
+		allResults = append(allResults, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allResults, nil
+}
+
+// ForEachResult fetches the results of a completed search job like Results,
+// but calls fn with each row as it is decoded from the response instead of
+// collecting them, so memory use is bounded by a single row rather than the
+// result set. fn may keep the row. An error from fn stops the fetch and is
+// returned as is.
+func (c *Client) ForEachResult(ctx context.Context, sid string, opts ResultsOptions, fn func(row json.RawMessage) error) error {
+	return c.forEachResultsPage(ctx, sid, opts, "json", func(body io.Reader) error {
+		return forEachPageRow(body, fn)
+	})
+}
+
+// forEachPageRow calls fn with each element of the "results" array of a JSON
+// results page read from body. A page without a "results" key holds no rows.
+func forEachPageRow(body io.Reader, fn func(row json.RawMessage) error) error {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("failed to decode results page: not a JSON object")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode results page: %w", err)
+		}
+		if key != "results" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to decode results page: %w", err)
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return errors.New(`failed to decode results page: "results" is not an array`)
+		}
+		for dec.More() {
+			var row json.RawMessage
+			if err := dec.Decode(&row); err != nil {
+				return fmt.Errorf("failed to decode results page: %w", err)
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to decode results page: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResultsJSON writes the results of a completed search job to w as a single
+// compact {"results":[...]} document. The rows are copied byte for byte from
+// Splunk's JSON output mode instead of being decoded and re-encoded, so number
+// formatting and key order are exactly Splunk's; the rows of all pages are
+// joined into one array. It returns the number of result rows written. opts is
+// interpreted as in Results, except that FirstColumn is ignored.
+func (c *Client) ResultsJSON(ctx context.Context, sid string, opts ResultsOptions, w io.Writer) (int, error) {
+	if _, err := io.WriteString(w, `{"results":[`); err != nil {
+		return 0, err
+	}
+	rows := 0
+	err := c.forEachResultsPage(ctx, sid, opts, "json", func(body io.Reader) error {
+		page, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read results page: %w", err)
+		}
+		start, end, n, err := resultsArraySpan(page)
+		if err != nil {
+			return fmt.Errorf("failed to decode results page: %w", err)
+		}
+		if n == 0 {
+			return nil
+		}
+		if rows > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(bytes.TrimSpace(page[start:end])); err != nil {
+			return err
+		}
+		rows += n
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	_, err = io.WriteString(w, "]}\n")
+	return rows, err
+}
+
+// resultsArraySpan locates the elements of the "results" array in a JSON
+// results page, returning the byte range between its brackets and the number
+// of rows in it. The rows are only scanned, never decoded into Go values. A
+// page without a "results" key holds no rows.
+func resultsArraySpan(page []byte) (start, end, rows int, err error) {
+	dec := json.NewDecoder(bytes.NewReader(page))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return 0, 0, 0, errors.New("results page is not a JSON object")
+	}
+	var skip json.RawMessage // reused, so scanning a row allocates nothing
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if key != "results" {
+			if err := dec.Decode(&skip); err != nil {
+				return 0, 0, 0, err
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return 0, 0, 0, errors.New(`"results" is not an array`)
+		}
+		start = int(dec.InputOffset())
+		for dec.More() {
+			if err := dec.Decode(&skip); err != nil {
+				return 0, 0, 0, err
+			}
+			rows++
+		}
+		if _, err := dec.Token(); err != nil {
+			return 0, 0, 0, err
+		}
+		return start, int(dec.InputOffset()) - 1, rows, nil
+	}
+	return 0, 0, 0, nil
+}
+
+// ResultsCSV streams the results of a completed search job to w as CSV, using
+// Splunk's CSV output mode. Every page Splunk returns starts with its own header
+// row; only the first is written, and an error is returned if a later page's
+// columns differ from it. An empty result set produces no output. It returns the
+// number of result rows written. opts is interpreted as in Results.
+func (c *Client) ResultsCSV(ctx context.Context, sid string, opts ResultsOptions, w io.Writer) (int, error) {
+	cw := csv.NewWriter(w)
+	var header []string
+	var order []int // column permutation for opts.FirstColumn; nil to keep the order
+	rows := 0
+	err := c.forEachResultsPage(ctx, sid, opts, "csv", func(body io.Reader) error {
+		r := csv.NewReader(body)
+		r.FieldsPerRecord = -1
+		pageHeader, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV results page: %w", err)
+		}
+
+		if header == nil {
+			header = pageHeader
+			order = firstColumnOrder(header, opts.FirstColumn)
+			if err := cw.Write(reorder(header, order)); err != nil {
+				return err
+			}
+		} else if !slices.Equal(header, pageHeader) {
+			return fmt.Errorf("inconsistent CSV columns across result pages: %v vs %v", header, pageHeader)
+		}
+
+		for {
+			record, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read CSV results page: %w", err)
+			}
+			if err := cw.Write(reorder(record, order)); err != nil {
+				return err
+			}
+			rows++
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return rows, err
+	}
+	cw.Flush()
+	return rows, cw.Error()
+}
+
+// firstColumnOrder returns the column permutation that moves the column named
+// first to the front, or nil if it is absent or already first.
+func firstColumnOrder(header []string, first string) []int {
+	i := slices.Index(header, first)
+	if first == "" || i <= 0 {
+		return nil
+	}
+	order := []int{i}
+	for j := range header {
+		if j != i {
+			order = append(order, j)
+		}
+	}
+	return order
+}
+
+// reorder returns record with its columns permuted by order. Records shorter
+// than the header are returned unchanged.
+func reorder(record []string, order []int) []string {
+	if order == nil || len(record) != len(order) {
+		return record
+	}
+	out := make([]string, len(order))
+	for i, j := range order {
+		out[i] = record[j]
+	}
+	return out
+}
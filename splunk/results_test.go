@@ -0,0 +1,222 @@
+package splunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newResultsServer serves a finished job "job.1" with resultCount results.
+// page writes the results page for offset and count in the requested output
+// mode.
+func newResultsServer(t *testing.T, resultCount int, page func(w io.Writer, mode string, offset, count int)) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/results") {
+			q := r.URL.Query()
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			count, _ := strconv.Atoi(q.Get("count"))
+			page(w, q.Get("output_mode"), offset, count)
+			return
+		}
+		fmt.Fprintf(w, `{"entry":[{"content":{"isDone":true,"dispatchState":"DONE","resultCount":%d}}]}`, resultCount)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// csvPages serves n rows "i,host<i>" as CSV pages, each with its own header
+// row as Splunk sends it. header returns the header of the page at offset.
+func csvPages(header func(offset int) string) func(w io.Writer, mode string, offset, count int) {
+	return func(w io.Writer, mode string, offset, count int) {
+		io.WriteString(w, header(offset)+"\n")
+		for i := offset; i < offset+count; i++ {
+			fmt.Fprintf(w, "%d,host%d\n", i, i)
+		}
+	}
+}
+
+func TestResultsCSV(t *testing.T) {
+	sameHeader := func(int) string { return "n,host" }
+	tests := []struct {
+		name     string
+		rows     int
+		header   func(offset int) string
+		wantRows int
+		wantErr  string
+	}{
+		{name: "one page", rows: 3, header: sameHeader, wantRows: 3},
+		{name: "several pages", rows: 2*maxResultsPerPage + 5, header: sameHeader, wantRows: 2*maxResultsPerPage + 5},
+		{name: "empty", rows: 0, header: sameHeader, wantRows: 0},
+		{
+			name: "mismatched columns",
+			rows: maxResultsPerPage + 1,
+			header: func(offset int) string {
+				if offset > 0 {
+					return "n,host,extra"
+				}
+				return "n,host"
+			},
+			wantErr: "inconsistent CSV columns",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newResultsServer(t, tt.rows, csvPages(tt.header))
+			var out bytes.Buffer
+			n, err := client.ResultsCSV(context.Background(), "job.1", ResultsOptions{}, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.wantRows {
+				t.Errorf("rows = %d, want %d", n, tt.wantRows)
+			}
+			if tt.rows == 0 {
+				if out.Len() != 0 {
+					t.Errorf("empty result set wrote %q", out.String())
+				}
+				return
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) != tt.wantRows+1 {
+				t.Fatalf("got %d lines, want a header and %d rows", len(lines), tt.wantRows)
+			}
+			if strings.Count(out.String(), "n,host\n") != 1 || lines[0] != "n,host" {
+				t.Errorf("header not written exactly once at the top")
+			}
+			if last := lines[len(lines)-1]; last != fmt.Sprintf("%d,host%d", tt.rows-1, tt.rows-1) {
+				t.Errorf("last row = %q", last)
+			}
+		})
+	}
+}
+
+func TestResultsCSVFirstColumn(t *testing.T) {
+	client := newResultsServer(t, 2, csvPages(func(int) string { return "n,host" }))
+	var out bytes.Buffer
+	if _, err := client.ResultsCSV(context.Background(), "job.1", ResultsOptions{FirstColumn: "host"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "host,n\nhost0,0\nhost1,1\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+// jsonPages serves rows {"n":"<i>"} as JSON results pages, with the metadata
+// keys Splunk puts around the "results" array.
+func jsonPages(w io.Writer, mode string, offset, count int) {
+	io.WriteString(w, `{"preview":false,"init_offset":`+strconv.Itoa(offset)+`,"messages":[],"fields":[{"name":"n"}],"results":[`)
+	for i := offset; i < offset+count; i++ {
+		if i > offset {
+			io.WriteString(w, ",")
+		}
+		fmt.Fprintf(w, `{"n":"%d"}`, i)
+	}
+	io.WriteString(w, `],"highlighted":{}}`)
+}
+
+func TestForEachResult(t *testing.T) {
+	total := 2*maxResultsPerPage + 5
+	client := newResultsServer(t, total, jsonPages)
+
+	next := 0
+	err := client.ForEachResult(context.Background(), "job.1", ResultsOptions{}, func(row json.RawMessage) error {
+		if want := fmt.Sprintf(`{"n":"%d"}`, next); string(row) != want {
+			return fmt.Errorf("row %d = %s, want %s", next, row, want)
+		}
+		next++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != total {
+		t.Errorf("got %d rows, want %d", next, total)
+	}
+}
+
+func TestForEachResultStopsOnError(t *testing.T) {
+	client := newResultsServer(t, 2*maxResultsPerPage, jsonPages)
+	stop := errors.New("stop")
+
+	rows := 0
+	err := client.ForEachResult(context.Background(), "job.1", ResultsOptions{}, func(json.RawMessage) error {
+		rows++
+		if rows == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("err = %v, want the callback's error", err)
+	}
+	if rows != 10 {
+		t.Errorf("callback ran %d times after it failed, want it to stop at 10", rows)
+	}
+}
+
+func TestForEachResultMalformedPage(t *testing.T) {
+	client := newResultsServer(t, 1, func(w io.Writer, mode string, offset, count int) {
+		io.WriteString(w, `{"results":{"n":"0"}}`)
+	})
+	err := client.ForEachResult(context.Background(), "job.1", ResultsOptions{}, func(json.RawMessage) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "failed to decode results page") {
+		t.Errorf("err = %v, want a decode error", err)
+	}
+}
+
+func TestForEachResultWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		offset, limit int
+		wantRows      int
+		wantErr       string
+	}{
+		{name: "within total", offset: 2, limit: 3, wantRows: 3},
+		{name: "up to total", offset: 5, limit: 5, wantRows: 5},
+		{name: "offset to end", offset: 4, limit: 0, wantRows: 6},
+		{name: "limit caps without offset", offset: 0, limit: 50, wantRows: 10},
+		{name: "window beyond total", offset: 8, limit: 5, wantErr: "extends beyond the 10 results"},
+		{name: "offset beyond total", offset: 11, limit: 0, wantErr: "offset 11 is beyond"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newResultsServer(t, 10, jsonPages)
+			rows := 0
+			err := client.ForEachResult(context.Background(), "job.1", ResultsOptions{Offset: tt.offset, Limit: tt.limit}, func(json.RawMessage) error {
+				rows++
+				return nil
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("rows = %d, want %d", rows, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package splunk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// sendWithRetry sends req, retrying up to cfg.Retries times on rate limiting
+// (429), transient gateway errors (502, 503, 504), and, for idempotent requests,
+// network errors. A Retry-After header is honored when present; otherwise the
+// delay grows exponentially. No retry is attempted if the wait would run past
+// the request context's deadline.
+func (c *Client) sendWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := c.client.Do(req)
+		if attempt >= c.cfg.Retries {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			// The body has been consumed and cannot be replayed.
+			return resp, err
+		}
+
+		wait, retry := retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			c.Log.Debugf("Request to %s returned %s; retrying in %s (attempt %d of %d)\n", req.URL.Path, resp.Status, wait, attempt+1, c.cfg.Retries)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			c.Log.Debugf("Request to %s failed: %v; retrying in %s (attempt %d of %d)\n", req.URL.Path, err, wait, attempt+1, c.cfg.Retries)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type idempotentKey struct{}
+
+// withIdempotent marks requests made with ctx as safe to resend after a
+// network error, even though their method is not idempotent.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// retryDelay decides whether a request should be retried and how long to wait first.
+func retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := retryBaseDelay << attempt
+	if backoff > retryMaxDelay {
+		backoff = retryMaxDelay
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		// Without a response we can't know whether the server acted on the
+		// request, so only idempotent requests are retried.
+		return backoff, req.Method == http.MethodGet || req.Method == http.MethodHead || isIdempotent(req.Context())
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, true
+		}
+		return backoff, true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return backoff, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
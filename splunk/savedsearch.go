@@ -0,0 +1,168 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ResolveSavedSearchNamespace finds the namespace (owner, app) in which the
+// named saved search lives by querying saved/searches across all namespaces.
+// Shared (app or global) searches resolve to the "nobody" owner. If the search
+// exists in several apps, the configured app is preferred; otherwise an error
+// listing the candidates is returned.
+func (c *Client) ResolveSavedSearchNamespace(name string) (string, string, error) {
+	endpoint, err := c.createNamespacedAPIURL("-", "-", "saved", "searches")
+	if err != nil {
+		return "", "", err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", "", err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	q.Add("count", "0")
+	q.Add("search", fmt.Sprintf("name=%q", name))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return "", "", err
+	}
+
+	var page struct {
+		Entry []struct {
+			Name string `json:"name"`
+			ACL  struct {
+				App     string `json:"app"`
+				Owner   string `json:"owner"`
+				Sharing string `json:"sharing"`
+			} `json:"acl"`
+		} `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return "", "", fmt.Errorf("failed to decode saved searches response: %w", err)
+	}
+
+	type namespace struct{ owner, app string }
+	var matches []namespace
+	for _, e := range page.Entry {
+		if e.Name != name {
+			continue
+		}
+		owner := "nobody"
+		if e.ACL.Sharing == "user" {
+			owner = e.ACL.Owner
+		}
+		matches = append(matches, namespace{owner: owner, app: e.ACL.App})
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", "", fmt.Errorf("saved search '%s' not found in any namespace", name)
+	case 1:
+		return matches[0].owner, matches[0].app, nil
+	}
+	var candidates []string
+	for _, m := range matches {
+		if m.app == c.cfg.App {
+			return m.owner, m.app, nil
+		}
+		candidates = append(candidates, m.owner+"/"+m.app)
+	}
+	return "", "", fmt.Errorf("saved search '%s' exists in multiple namespaces (%s); use --app to choose one", name, strings.Join(candidates, ", "))
+}
+
+// SavedSearchOptions holds the optional settings for CreateSavedSearch.
+type SavedSearchOptions struct {
+	Earliest string
+	Latest   string
+	// Update allows an existing saved search with the same name to be
+	// overwritten. Without it, creating a duplicate fails.
+	Update bool
+}
+
+// CreateSavedSearch creates a saved search in the configured namespace, scheduled
+// with the given cron expression if schedule is non-empty. It returns the REST
+// URL of the saved search.
+func (c *Client) CreateSavedSearch(name, spl, schedule string, opts SavedSearchOptions) (string, error) {
+	objectURL, err := c.createAPIURL("saved", "searches", name)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("search", spl)
+	form.Set("output_mode", "json")
+	if schedule != "" {
+		form.Set("cron_schedule", schedule)
+		form.Set("is_scheduled", "1")
+	}
+	if opts.Earliest != "" {
+		form.Set("dispatch.earliest_time", opts.Earliest)
+	}
+	if opts.Latest != "" {
+		form.Set("dispatch.latest_time", opts.Latest)
+	}
+
+	if opts.Update {
+		status, err := c.postSavedSearch(objectURL, form)
+		if err != nil {
+			return "", err
+		}
+		if status != http.StatusNotFound {
+			return objectURL, nil
+		}
+		// Nothing to update; fall through and create it.
+	}
+
+	endpoint, err := c.createAPIURL("saved", "searches")
+	if err != nil {
+		return "", err
+	}
+	form.Set("name", name)
+	status, err := c.postSavedSearch(endpoint, form)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusConflict {
+		return "", fmt.Errorf("saved search '%s' already exists; use --update to overwrite it", name)
+	}
+	return objectURL, nil
+}
+
+// postSavedSearch POSTs a saved search form and returns the status code for
+// 404 and 409 responses, which callers handle; any other failure is an error.
+func (c *Client) postSavedSearch(endpoint string, form url.Values) (int, error) {
+	c.Log.Debugf(`Request: POST %s
+`, endpoint)
+
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNotFound, http.StatusConflict:
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, c.handleFailedResponse(resp, http.StatusOK)
+}
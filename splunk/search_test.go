@@ -0,0 +1,139 @@
+package splunk
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// largeSPL returns a search of at least n bytes with characters that need form
+// encoding, on many lines.
+func largeSPL(n int) string {
+	var b strings.Builder
+	b.WriteString("index=main")
+	for i := 0; b.Len() < n; i++ {
+		b.WriteString("\n| eval f_")
+		b.WriteString(strings.Repeat("x", i%7))
+		b.WriteString(`="a&b=c%20d+é 'q' \"r\""`)
+	}
+	return b.String()
+}
+
+func TestPostSearchFormLargeSPL(t *testing.T) {
+	spl := largeSPL(3 << 20)
+	for _, compress := range []bool{false, true} {
+		t.Run(map[bool]string{false: "plain", true: "gzip"}[compress], func(t *testing.T) {
+			var gotSearch string
+			var gotLength int64
+			var gotChunked bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotLength = r.ContentLength
+				gotChunked = len(r.TransferEncoding) > 0
+				var body io.Reader = r.Body
+				if r.Header.Get("Content-Encoding") == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("bad gzip body: %v", err)
+						return
+					}
+					body = zr
+				}
+				data, err := io.ReadAll(body)
+				if err != nil {
+					t.Errorf("reading body: %v", err)
+					return
+				}
+				form, err := url.ParseQuery(string(data))
+				if err != nil {
+					t.Errorf("parsing form: %v", err)
+					return
+				}
+				gotSearch = form.Get("search")
+				if r.URL.RawQuery != "" {
+					t.Errorf("SPL sent in the URL: %.100s", r.URL.RawQuery)
+				}
+				w.WriteHeader(http.StatusCreated)
+				io.WriteString(w, `{"sid":"big.1"}`)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz"}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.postSearchForm(srv.URL+"/services/search/jobs", searchForm(spl, SearchOptions{}).Encode(), false, compress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if gotChunked || gotLength <= 0 {
+				t.Errorf("request sent without Content-Length (length %d, chunked %t)", gotLength, gotChunked)
+			}
+			if gotSearch != "search "+spl {
+				t.Errorf("SPL of %d bytes arrived as %d bytes and differs", len(spl)+len("search "), len(gotSearch))
+			}
+		})
+	}
+}
+
+func TestStartSearchTooLarge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(&Config{Host: srv.URL, Token: "zzzzzzzz"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = client.StartSearch(largeSPL(1<<20), SearchOptions{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 413") {
+		t.Fatalf("StartSearch error = %v, want an HTTP 413 error", err)
+	}
+}
+
+func TestSearchFormTimeFormat(t *testing.T) {
+	tests := []struct {
+		name                           string
+		earliest, latest, timeFormat   string
+		wantEarliest, wantLatest, want string
+	}{
+		{name: "no time range"},
+		{name: "relative", earliest: "-1h@h", latest: "now", wantEarliest: "-1h@h", wantLatest: "now"},
+		{name: "epoch", earliest: "1672531200", latest: "1672617600", wantEarliest: "1672531200", wantLatest: "1672617600", want: "%s"},
+		{name: "epoch and relative", earliest: "1672531200", latest: "now", wantEarliest: "1672531200", wantLatest: "now", want: "%s"},
+		{name: "fractional epoch", earliest: "1672531200.5", latest: "1672617600", wantEarliest: "1672531200.500", wantLatest: "1672617600.000", want: "%s.%Q"},
+		{name: "exponent is not epoch", earliest: "1e9", wantEarliest: "1e9"},
+		{
+			name: "formatted", earliest: "2024-05-01 10:00:00", latest: "2024-05-02 10:00:00", timeFormat: "%Y-%m-%d %H:%M:%S",
+			wantEarliest: "2024-05-01 10:00:00", wantLatest: "2024-05-02 10:00:00", want: "%Y-%m-%d %H:%M:%S",
+		},
+		{
+			name: "explicit format wins over epoch detection", earliest: "20240501", timeFormat: "%Y%m%d",
+			wantEarliest: "20240501", want: "%Y%m%d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := searchForm("index=main", SearchOptions{Earliest: tt.earliest, Latest: tt.latest, TimeFormat: tt.timeFormat})
+			if got := form.Get("earliest_time"); got != tt.wantEarliest {
+				t.Errorf("earliest_time = %q, want %q", got, tt.wantEarliest)
+			}
+			if got := form.Get("latest_time"); got != tt.wantLatest {
+				t.Errorf("latest_time = %q, want %q", got, tt.wantLatest)
+			}
+			if got := form.Get("time_format"); got != tt.want {
+				t.Errorf("time_format = %q, want %q", got, tt.want)
+			}
+			if _, ok := form["time_format"]; ok != (tt.want != "") {
+				t.Errorf("time_format present = %t, want %t", ok, tt.want != "")
+			}
+		})
+	}
+}
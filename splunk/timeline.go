@@ -0,0 +1,83 @@
+package splunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TimelineBucket is one bucket of a job's timeline: the number of matching
+// events in the DurationSecs seconds starting at Earliest.
+type TimelineBucket struct {
+	Earliest     time.Time `json:"earliest"`
+	DurationSecs float64   `json:"durationSecs"`
+	Count        int       `json:"count"`
+	Finalized    bool      `json:"finalized"`
+}
+
+// Timeline is the event distribution of a job over its time range, as
+// returned by Timeline.
+type Timeline struct {
+	SID        string           `json:"sid"`
+	EventCount int              `json:"eventCount"`
+	Buckets    []TimelineBucket `json:"buckets"`
+}
+
+// Timeline fetches the timeline of job sid. Splunk only keeps one for jobs
+// dispatched with SearchOptions.StatusBuckets > 0; for other jobs it has no
+// buckets.
+func (c *Client) Timeline(sid string) (*Timeline, error) {
+	endpoint, err := c.createAPIURL("search", "jobs", sid, "timeline")
+	if err != nil {
+		return nil, err
+	}
+	c.Log.Debugf(`Request: GET %s
+`, endpoint)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("output_mode", "json")
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := c.handleFailedResponse(resp, http.StatusOK); err != nil {
+		return nil, jobError(sid, err)
+	}
+
+	var page struct {
+		EventCount int `json:"event_count"`
+		Buckets    []struct {
+			EarliestTime float64 `json:"earliest_time"`
+			Duration     float64 `json:"duration"`
+			TotalCount   int     `json:"total_count"`
+			IsFinalized  bool    `json:"is_finalized"`
+		} `json:"buckets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode timeline response: %w", err)
+	}
+
+	timeline := &Timeline{
+		SID:        sid,
+		EventCount: page.EventCount,
+		Buckets:    make([]TimelineBucket, 0, len(page.Buckets)),
+	}
+	for _, b := range page.Buckets {
+		timeline.Buckets = append(timeline.Buckets, TimelineBucket{
+			Earliest:     time.UnixMilli(int64(b.EarliestTime * 1000)).UTC(),
+			DurationSecs: b.Duration,
+			Count:        b.TotalCount,
+			Finalized:    b.IsFinalized,
+		})
+	}
+	return timeline, nil
+}
@@ -0,0 +1,40 @@
+package splunk
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
+const (
+	managementPort = "8089"
+	defaultWebPort = "8000"
+)
+
+// JobInspectorURL builds the Splunk Web job inspector URL for a job. webHost is
+// the base URL of Splunk Web; if empty, it is derived from the management host
+// by swapping the default management port (8089) for the default web port
+// (8000). The app defaults to "search".
+func JobInspectorURL(host, webHost, app, sid string) (string, error) {
+	base := webHost
+	if base == "" {
+		base = host
+	}
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid host URL '%s'", base)
+	}
+	if webHost == "" && u.Port() == managementPort {
+		u.Host = net.JoinHostPort(u.Hostname(), defaultWebPort)
+	}
+	if app == "" {
+		app = "search"
+	}
+
+	u = u.JoinPath("en-US", "manager", app, "job_inspector")
+	q := url.Values{}
+	q.Set("sid", sid)
+	q.Set("namespace", app)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}